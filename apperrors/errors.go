@@ -0,0 +1,84 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of domain errors. Services wrap them in an *Error so the error handler
+// can pick the HTTP status with errors.Is, whatever the message says.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrInvalidID    = errors.New("invalid ID")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrPrecondition = errors.New("precondition failed")
+)
+
+// Error is a domain error: a kind, a message that is safe to show to clients,
+// and for validation errors, a message per field
+type Error struct {
+	Kind    error
+	Message string
+	Fields  map[string]string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func newError(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...)}
+}
+
+// BadRequest reports malformed input, such as an unparsable query parameter
+func BadRequest(format string, args ...interface{}) error {
+	return newError(ErrBadRequest, format, args...)
+}
+
+// InvalidParams reports malformed query parameters, with a message per parameter
+func InvalidParams(message string, params map[string]string) error {
+	return &Error{Kind: ErrBadRequest, Message: message, Fields: params}
+}
+
+// InvalidID reports an ID that is not a valid ObjectID
+func InvalidID(format string, args ...interface{}) error {
+	return newError(ErrInvalidID, format, args...)
+}
+
+// Unauthorized reports missing or wrong credentials
+func Unauthorized(format string, args ...interface{}) error {
+	return newError(ErrUnauthorized, format, args...)
+}
+
+// Forbidden reports an authenticated user acting on something they may not touch
+func Forbidden(format string, args ...interface{}) error {
+	return newError(ErrForbidden, format, args...)
+}
+
+// NotFound reports a missing resource
+func NotFound(format string, args ...interface{}) error {
+	return newError(ErrNotFound, format, args...)
+}
+
+// Conflict reports a request that clashes with the current state, such as a duplicate
+func Conflict(format string, args ...interface{}) error {
+	return newError(ErrConflict, format, args...)
+}
+
+// PreconditionFailed reports a conditional request, such as If-Match, whose condition does not hold
+func PreconditionFailed(format string, args ...interface{}) error {
+	return newError(ErrPrecondition, format, args...)
+}
+
+// Validation reports well-formed input that breaks a business rule, optionally per field
+func Validation(message string, fields map[string]string) error {
+	return &Error{Kind: ErrValidation, Message: message, Fields: fields}
+}
@@ -1,28 +1,79 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
 	"job-portal/config"
 	"job-portal/controllers"
+	"job-portal/mailer"
 	"job-portal/middlewares"
+	"job-portal/migrations"
+	"job-portal/repositories"
 	"job-portal/routers"
+	"job-portal/scheduler"
 	"job-portal/services"
+	"job-portal/storage"
+	"job-portal/token"
+	"job-portal/utils"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
 )
 
 type CustomValidator struct {
 	validator *validator.Validate
 }
 
+// NewCustomValidator creates a validator that reports fields by their JSON names
+func NewCustomValidator() *CustomValidator {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return &CustomValidator{validator: v}
+}
+
 func (cv *CustomValidator) Validate(i interface{}) error {
 	return cv.validator.Struct(i)
 }
 
 func main() {
+	// Load and validate the configuration before anything else
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	jwtManager, err := token.LoadManager(token.Options{
+		Algorithm:              cfg.JWTAlgorithm,
+		Secret:                 cfg.JWTSecret,
+		PrivateKeyFile:         cfg.JWTPrivateKeyFile,
+		KeyID:                  cfg.JWTKeyID,
+		PreviousSecrets:        cfg.JWTPreviousSecrets,
+		PreviousPublicKeyFiles: cfg.JWTPreviousPublicKeys,
+	})
+	if err != nil {
+		log.Fatal("Failed to load JWT keys: ", err)
+	}
+	csrf := middlewares.CSRFConfig{TrustedOrigins: cfg.CORSOrigins} // The CORS origins may also send cookie-authenticated requests
+
 	// Initialize Echo
 	e := echo.New()
 
@@ -34,7 +85,7 @@ func main() {
 	e.Use(middleware.Recover())   // Recover from panics
 	e.Use(middleware.RequestID()) // Add a unique request ID for each request
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"https://job-portal-frontend-pink.vercel.app", "http://localhost:3000"},
+		AllowOrigins: cfg.CORSOrigins,
 		AllowMethods: []string{
 		  http.MethodGet,
 		  http.MethodPost,
@@ -43,38 +94,204 @@ func main() {
 		  http.MethodDelete,
 		  http.MethodOptions, // Allow OPTIONS for preflight
 		},
-		AllowHeaders: []string{"Content-Type", "Authorization"},
+		AllowHeaders: []string{"Content-Type", "Authorization", middlewares.CSRFHeaderName, middlewares.OrgHeaderName, "If-Match", "If-None-Match"},
+		ExposeHeaders: []string{"ETag"},
 		AllowCredentials: true,
 	  }))
 	  
 	// e.Use(middleware.Secure())                                              // Add secure headers (e.g., X-Frame-Options, HSTS)
-	e.Use(middleware.RateLimiter(middleware.NewRateLimiterMemoryStore(20))) // Rate limit: 20 requests per second
-	e.Use(middleware.BodyLimit("2M"))                                       // Limit request body size to 2MB
+	e.Use(middleware.RateLimiter(middleware.NewRateLimiterMemoryStore(rate.Limit(cfg.RateLimit)))) // Rate limit: RATE_LIMIT requests per second
+	e.Use(middleware.BodyLimit(cfg.BodyLimit))                                                     // Limit request body size to BODY_LIMIT
 
 	// Validator
-	e.Validator = &CustomValidator{validator: validator.New()}
+	e.Validator = NewCustomValidator()
 
 	// Connect to the database
-	config.Connect()
+	if err := config.Connect(cfg.MongoURI); err != nil {
+		log.Fatal(err)
+	}
+
+	// Indexes are created by cmd/migrate; searching needs the text index among them
+	warnPendingMigrations(cfg)
 
 	e.GET("/", func(c echo.Context) error {
 		return c.String(http.StatusOK, "Hello from golang server!")
 	})
 
+	// Initialize session service, used by the JWT middleware to reject revoked sessions
+	sessionCollection := config.GetCollection(cfg.DatabaseName, "sessions")
+	sessionService := services.NewSessionService(sessionCollection)
+
+	// Initialize one-time token service and mailer, used for emailed links
+	tokenCollection := config.GetCollection(cfg.DatabaseName, "tokens")
+	tokenService := services.NewTokenService(tokenCollection)
+	mail := mailer.NewLogMailer(cfg.MailOutboxDir) // Messages are logged and written to MAIL_OUTBOX_DIR
+
 	// Initialize user service and controller
-	userCollection := config.GetCollection("jobportal", "users")
-	userService := services.NewUserService(userCollection)
-	userController := controllers.NewUserController(userService)
+	userCollection := config.GetCollection(cfg.DatabaseName, "users")
+	userService := services.NewUserService(repositories.NewMongoUserRepository(userCollection), sessionService, tokenService, jwtManager, mail, cfg.FrontendURL)
+	userController := controllers.NewUserController(userService, cfg.CookieSecure, csrf)
+
+	// Initialize organization service, used by the ActiveOrg middleware to check memberships
+	jobRepository := repositories.NewMongoJobRepository(config.GetCollection(cfg.DatabaseName, "jobs"))
+	organizationRepository := repositories.NewMongoOrganizationRepository(
+		config.GetCollection(cfg.DatabaseName, "organizations"),
+		config.GetCollection(cfg.DatabaseName, "memberships"),
+		config.GetCollection(cfg.DatabaseName, "invitations"))
+	organizationService := services.NewOrganizationService(organizationRepository, jobRepository, mail, cfg.FrontendURL)
+	organizationController := controllers.NewOrganizationController(organizationService)
 
 	// Initialize job service and controller
-	jobCollection := config.GetCollection("jobportal", "jobs")
-	jobService := services.NewJobService(jobCollection)
+	companyRepository := repositories.NewMongoCompanyRepository(config.GetCollection(cfg.DatabaseName, "companies"))
+	jobService := services.NewJobService(jobRepository, cursorSigner(cfg.CursorSecret), cfg.ExchangeRates, cfg.Geocoder, companyRepository, organizationService)
+	normalizeSalaries(jobService)
 	jobController := controllers.NewJobController(jobService)
 
-	// Register routes
-	routers.RegisterUserRoutes(e, userController)
-	routers.RegisterJobRoutes(e, jobController)
+	// Initialize company service and controller
+	companyService := services.NewCompanyService(companyRepository, jobRepository, organizationService, jobService)
+	companyController := controllers.NewCompanyController(companyService)
+
+	// Initialize profile service and controller; resumes are kept in UPLOAD_DIR
+	profileRepository := repositories.NewMongoProfileRepository(config.GetCollection(cfg.DatabaseName, "profiles"))
+	profileService := services.NewProfileService(profileRepository, storage.NewLocalStore(cfg.UploadDir))
+	profileController := controllers.NewProfileController(profileService)
+
+	// Initialize application service and controller; reviewers see the applicant's profile and resume
+	applicationRepository := repositories.NewMongoApplicationRepository(config.GetCollection(cfg.DatabaseName, "applications"))
+	applicationService := services.NewApplicationService(applicationRepository, jobService, profileService)
+	applicationController := controllers.NewApplicationController(applicationService)
+
+	// Initialize key controller, which publishes the JWT verification keys
+	keyController := controllers.NewKeyController(jwtManager)
+
+	// Register routes, guarded by middlewares that check tokens against live sessions and memberships
+	routeConfig := routers.Config{
+		JWT:           middlewares.JWTConfig{Tokens: jwtManager, Sessions: sessionService, CSRF: csrf},
+		VerifiedEmail: middlewares.VerifiedEmailConfig{Enforce: cfg.RequireEmailVerification},
+		Org:           middlewares.OrgConfig{Memberships: organizationService},
+	}
+	routers.RegisterKeyRoutes(e, keyController)
+	routers.RegisterUserRoutes(e, userController, routeConfig)
+	routers.RegisterJobRoutes(e, jobController, applicationController, routeConfig)
+	routers.RegisterCompanyRoutes(e, companyController, routeConfig)
+	routers.RegisterOrganizationRoutes(e, organizationController, routeConfig)
+	routers.RegisterProfileRoutes(e, profileController, routeConfig)
+
+	// Background tasks, coordinated across replicas through leases in Mongo
+	jobScheduler := scheduler.New(scheduler.NewMongoLocker(config.GetCollection(cfg.DatabaseName, "scheduler_locks")))
+	registerTasks(jobScheduler, cfg, jobService, sessionService, tokenService)
+	if cfg.SchedulerEnabled {
+		jobScheduler.Start()
+	}
 
 	// Start the server
-	log.Fatal(e.Start(":8080"))
+	go func() {
+		if err := e.Start(cfg.Address()); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// Shut down gracefully on SIGINT or SIGTERM, letting requests and running tasks finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		log.Println("Server shutdown:", err)
+	}
+	if err := jobScheduler.Stop(shutdownCtx); err != nil {
+		log.Println("Scheduler shutdown:", err)
+	}
+}
+
+// warnPendingMigrations logs a warning when the database schema is behind this build
+func warnPendingMigrations(cfg *config.Config) {
+	migrator, err := migrations.NewMigrator(config.DB.Database(cfg.DatabaseName), migrations.All(cfg.Geocoder))
+	if err != nil {
+		log.Fatal(err)
+	}
+	statuses, err := migrator.Status(context.Background())
+	if err != nil {
+		log.Println("Could not check migrations:", err)
+		return
+	}
+	pending := 0
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+	if pending > 0 {
+		log.Printf("Warning: %d pending database migration(s), run `go run ./cmd/migrate up`", pending)
+	}
+}
+
+// normalizeSalaries brings the annual salaries of stored jobs in line with the
+// configured exchange rates. Listings keep working with the old values if it fails.
+func normalizeSalaries(jobService *services.JobService) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	updated, err := jobService.NormalizeSalaries(ctx)
+	if err != nil {
+		log.Println("Could not normalize salaries:", err)
+		return
+	}
+	if updated > 0 {
+		log.Printf("Normalized the salaries of %d job(s) with the current exchange rates", updated)
+	}
+}
+
+// cursorSigner creates the signer for pagination cursors. Without a configured
+// secret a random one is used, so cursors stop working after a restart and are
+// not accepted by other replicas.
+func cursorSigner(secret string) *utils.Signer {
+	if secret != "" {
+		return utils.NewSigner([]byte(secret))
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Warning: CURSOR_SECRET is not set, pagination cursors will not survive a restart or work across replicas")
+	return utils.NewSigner(key)
+}
+
+// registerTasks adds the background tasks to the scheduler
+func registerTasks(s *scheduler.Scheduler, cfg *config.Config, jobService *services.JobService, sessionService *services.SessionService, tokenService *services.TokenService) {
+	tasks := []scheduler.Task{
+		{
+			Name:     "expire-jobs",
+			Schedule: cfg.JobExpirySchedule,
+			Run: func(ctx context.Context) error {
+				expired, err := jobService.ExpireJobs(ctx)
+				if expired > 0 {
+					log.Printf("Expired %d job(s) past their apply_by date", expired)
+				}
+				return err
+			},
+		},
+		{
+			Name:     "purge-sessions-and-tokens",
+			Schedule: cfg.PurgeSchedule,
+			Run: func(ctx context.Context) error {
+				sessions, err := sessionService.PurgeStale(ctx, services.RevokedSessionRetention)
+				if err != nil {
+					return err
+				}
+				tokens, err := tokenService.PurgeStale(ctx)
+				if err != nil {
+					return err
+				}
+				log.Printf("Purged %d stale session(s) and %d stale token(s)", sessions, tokens)
+				return nil
+			},
+		},
+	}
+	for _, task := range tasks {
+		if err := s.Register(task); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
@@ -0,0 +1,100 @@
+// Command migrate applies the versioned database migrations of the job portal.
+//
+//	migrate up [version]   apply pending migrations, up to version if given
+//	migrate down [steps]   roll back the last steps migrations (default 1)
+//	migrate status         list migrations and whether they are applied
+package main
+
+import (
+	"context"
+	"fmt"
+	"job-portal/config"
+	"job-portal/migrations"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	command, args := os.Args[1], os.Args[2:]
+
+	number := 0
+	if len(args) > 0 {
+		var err error
+		number, err = strconv.Atoi(args[0])
+		if err != nil || number <= 0 {
+			log.Fatalf("%s expects a positive number, got %q", command, args[0])
+		}
+	}
+
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := config.Connect(cfg.MongoURI); err != nil {
+		log.Fatal(err)
+	}
+	defer config.DB.Disconnect(context.Background())
+
+	migrator, err := migrations.NewMigrator(config.DB.Database(cfg.DatabaseName), migrations.All(cfg.Geocoder))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx, number)
+		for _, m := range applied {
+			fmt.Printf("applied   %04d %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Nothing to apply, the database is up to date")
+		}
+	case "down":
+		if number == 0 {
+			number = 1
+		}
+		rolledBack, err := migrator.Down(ctx, number)
+		for _, m := range rolledBack {
+			fmt.Printf("reverted  %04d %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(rolledBack) == 0 {
+			fmt.Println("Nothing to roll back")
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			if s.Unknown {
+				state += " (unknown to this build)"
+			}
+			fmt.Printf("%04d %-45s %s\n", s.Version, s.Name, state)
+		}
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate up [version] | down [steps] | status")
+	os.Exit(2)
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"job-portal/currency"
+	"job-portal/geo"
+	"job-portal/scheduler"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// Config holds every setting the server reads from the environment
+type Config struct {
+	Port                     string   // PORT
+	MongoURI                 string   // MONGO_URI (required)
+	DatabaseName             string   // MONGO_DB
+	JWTAlgorithm             string   // JWT_ALGORITHM: HS256, RS256 or EdDSA
+	JWTSecret                string   // JWT_SECRET (required for HS256)
+	JWTPrivateKeyFile        string   // JWT_PRIVATE_KEY_FILE (required for RS256 and EdDSA)
+	JWTKeyID                 string   // JWT_KEY_ID, kid of the signing key
+	JWTPreviousSecrets       []string // JWT_PREVIOUS_SECRETS, comma separated "secret" or "kid=secret", still accepted during rotation
+	JWTPreviousPublicKeys    []string // JWT_PREVIOUS_PUBLIC_KEY_FILES, comma separated "path" or "kid=path", still accepted during rotation
+	CORSOrigins              []string // CORS_ORIGINS, comma separated
+	RateLimit                float64  // RATE_LIMIT, requests per second
+	BodyLimit                string   // BODY_LIMIT, e.g. 2M
+	FrontendURL              string   // FRONTEND_URL, used to build links in emails
+	MailOutboxDir            string   // MAIL_OUTBOX_DIR, where the local mailer writes messages
+	UploadDir                string   // UPLOAD_DIR, where the local blob store keeps uploaded files such as resumes
+	RequireEmailVerification bool     // REQUIRE_EMAIL_VERIFICATION
+	CookieSecure             bool     // COOKIE_SECURE, set on HTTPS deployments
+	CursorSecret             string   // CURSOR_SECRET, signs pagination cursors; random per process when unset
+
+	ExchangeRates *currency.Rates // EXCHANGE_RATES_FILE, JSON table for normalizing salaries; built-in table when unset
+	Geocoder      geo.Geocoder    // GAZETTEER_FILE, JSON list of places for geocoding job locations; built-in list when unset
+
+	SchedulerEnabled  bool               // SCHEDULER_ENABLED, runs background tasks in this process
+	JobExpirySchedule scheduler.Schedule // JOB_EXPIRY_SCHEDULE, when to expire jobs past their apply_by date
+	PurgeSchedule     scheduler.Schedule // PURGE_SCHEDULE, when to delete stale sessions and tokens
+}
+
+// Address returns the address the HTTP server listens on
+func (c *Config) Address() string {
+	return ":" + c.Port
+}
+
+var bodyLimitPattern = regexp.MustCompile(`^[0-9]+[BKMGTP]?$`)
+
+// Load reads the configuration from the environment. Values from an optional
+// .env file are used for variables that are not already set.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .env file: %w", err)
+	}
+
+	var problems []string
+	cfg := &Config{
+		Port:                  getEnv("PORT", "8080"),
+		MongoURI:              os.Getenv("MONGO_URI"),
+		DatabaseName:          getEnv("MONGO_DB", "jobportal"),
+		JWTAlgorithm:          getEnv("JWT_ALGORITHM", "HS256"),
+		JWTSecret:             os.Getenv("JWT_SECRET"),
+		JWTPrivateKeyFile:     os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		JWTKeyID:              os.Getenv("JWT_KEY_ID"),
+		JWTPreviousSecrets:    splitList(os.Getenv("JWT_PREVIOUS_SECRETS")),
+		JWTPreviousPublicKeys: splitList(os.Getenv("JWT_PREVIOUS_PUBLIC_KEY_FILES")),
+		BodyLimit:             getEnv("BODY_LIMIT", "2M"),
+		FrontendURL:           strings.TrimRight(getEnv("FRONTEND_URL", "https://job-portal-frontend-pink.vercel.app"), "/"),
+		MailOutboxDir:         getEnv("MAIL_OUTBOX_DIR", "outbox"),
+		UploadDir:             getEnv("UPLOAD_DIR", "uploads"),
+		CursorSecret:          os.Getenv("CURSOR_SECRET"),
+	}
+
+	if port, err := strconv.Atoi(cfg.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, fmt.Sprintf("PORT must be a number between 1 and 65535, got %q", cfg.Port))
+	}
+
+	problems = append(problems, checkMongoURI(cfg.MongoURI)...)
+
+	switch cfg.JWTAlgorithm {
+	case "HS256":
+		if cfg.JWTSecret == "" {
+			problems = append(problems, "JWT_SECRET is required")
+		} else if len(cfg.JWTSecret) < 32 {
+			problems = append(problems, "JWT_SECRET must be at least 32 characters long")
+		}
+	case "RS256", "EdDSA":
+		if cfg.JWTPrivateKeyFile == "" {
+			problems = append(problems, fmt.Sprintf("JWT_PRIVATE_KEY_FILE is required when JWT_ALGORITHM is %s", cfg.JWTAlgorithm))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("JWT_ALGORITHM must be HS256, RS256 or EdDSA, got %q", cfg.JWTAlgorithm))
+	}
+
+	for _, origin := range splitList(getEnv("CORS_ORIGINS", "https://job-portal-frontend-pink.vercel.app,http://localhost:3000")) {
+		if !isHTTPURL(origin) {
+			problems = append(problems, fmt.Sprintf("CORS_ORIGINS contains an invalid origin %q", origin))
+			continue
+		}
+		cfg.CORSOrigins = append(cfg.CORSOrigins, origin)
+	}
+
+	rateLimit, err := strconv.ParseFloat(getEnv("RATE_LIMIT", "20"), 64)
+	if err != nil || rateLimit <= 0 {
+		problems = append(problems, "RATE_LIMIT must be a positive number of requests per second")
+	}
+	cfg.RateLimit = rateLimit
+
+	if !bodyLimitPattern.MatchString(cfg.BodyLimit) {
+		problems = append(problems, fmt.Sprintf("BODY_LIMIT must look like 2M or 512K, got %q", cfg.BodyLimit))
+	}
+
+	if !isHTTPURL(cfg.FrontendURL) {
+		problems = append(problems, fmt.Sprintf("FRONTEND_URL must be an http(s) URL, got %q", cfg.FrontendURL))
+	}
+
+	cfg.RequireEmailVerification, err = strconv.ParseBool(getEnv("REQUIRE_EMAIL_VERIFICATION", "true"))
+	if err != nil {
+		problems = append(problems, "REQUIRE_EMAIL_VERIFICATION must be true or false")
+	}
+	cfg.CookieSecure, err = strconv.ParseBool(getEnv("COOKIE_SECURE", "false"))
+	if err != nil {
+		problems = append(problems, "COOKIE_SECURE must be true or false")
+	}
+
+	if cfg.CursorSecret != "" && len(cfg.CursorSecret) < 32 {
+		problems = append(problems, "CURSOR_SECRET must be at least 32 characters long")
+	}
+
+	if cfg.ExchangeRates, err = currency.Load(os.Getenv("EXCHANGE_RATES_FILE")); err != nil {
+		problems = append(problems, "EXCHANGE_RATES_FILE: "+err.Error())
+	}
+
+	if cfg.Geocoder, err = geo.LoadGazetteer(os.Getenv("GAZETTEER_FILE")); err != nil {
+		problems = append(problems, "GAZETTEER_FILE: "+err.Error())
+	}
+
+	cfg.SchedulerEnabled, err = strconv.ParseBool(getEnv("SCHEDULER_ENABLED", "true"))
+	if err != nil {
+		problems = append(problems, "SCHEDULER_ENABLED must be true or false")
+	}
+	if cfg.JobExpirySchedule, err = scheduler.Parse(getEnv("JOB_EXPIRY_SCHEDULE", "@every 5m")); err != nil {
+		problems = append(problems, "JOB_EXPIRY_SCHEDULE: "+err.Error())
+	}
+	if cfg.PurgeSchedule, err = scheduler.Parse(getEnv("PURGE_SCHEDULE", "0 3 * * *")); err != nil {
+		problems = append(problems, "PURGE_SCHEDULE: "+err.Error())
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return cfg, nil
+}
+
+// LoadDatabase reads only the database settings and the gazetteer migrations
+// geocode with, for tools such as cmd/migrate that do not need the rest of the
+// server configuration
+func LoadDatabase() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .env file: %w", err)
+	}
+
+	cfg := &Config{
+		MongoURI:     os.Getenv("MONGO_URI"),
+		DatabaseName: getEnv("MONGO_DB", "jobportal"),
+	}
+	problems := checkMongoURI(cfg.MongoURI)
+	var err error
+	if cfg.Geocoder, err = geo.LoadGazetteer(os.Getenv("GAZETTEER_FILE")); err != nil {
+		problems = append(problems, "GAZETTEER_FILE: "+err.Error())
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return cfg, nil
+}
+
+func checkMongoURI(uri string) []string {
+	if uri == "" {
+		return []string{"MONGO_URI is required"}
+	}
+	if !strings.HasPrefix(uri, "mongodb://") && !strings.HasPrefix(uri, "mongodb+srv://") {
+		return []string{"MONGO_URI must start with mongodb:// or mongodb+srv://"}
+	}
+	return nil
+}
+
+// getEnv returns the value of the environment variable, or fallback if it is unset or empty
+func getEnv(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// splitList splits a comma separated value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isHTTPURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"job-portal/models"
+	"job-portal/services"
+	"job-portal/utils"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ApplicationController struct {
+	ApplicationService *services.ApplicationService
+}
+
+func NewApplicationController(applicationService *services.ApplicationService) *ApplicationController {
+	return &ApplicationController{ApplicationService: applicationService}
+}
+
+// ApplyHandler submits the current user's application to a job
+func (ac *ApplicationController) ApplyHandler(c echo.Context) error {
+	var application models.Application
+	if err := c.Bind(&application); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input").SetInternal(err)
+	}
+	if err := c.Validate(&application); err != nil {
+		return err // Reported per field by the custom error handler
+	}
+
+	userID, _ := c.Get("userID").(string)
+	if err := ac.ApplicationService.Apply(c.Param("id"), userID, &application); err != nil {
+		return err
+	}
+
+	return utils.SendResponse(c, http.StatusCreated, "Application submitted successfully", application)
+}
+
+// ListJobApplicationsHandler lists every application submitted to a job
+func (ac *ApplicationController) ListJobApplicationsHandler(c echo.Context) error {
+	userID, _ := c.Get("userID").(string)
+	role, _ := c.Get("role").(string)
+	applications, err := ac.ApplicationService.ListByJob(c.Param("id"), userID, role)
+	if err != nil {
+		return err
+	}
+
+	return utils.SendResponse(c, http.StatusOK, "Applications retrieved successfully", applications)
+}
+
+// GetApplicationHandler retrieves an application of a job with the applicant's profile
+func (ac *ApplicationController) GetApplicationHandler(c echo.Context) error {
+	userID, _ := c.Get("userID").(string)
+	role, _ := c.Get("role").(string)
+	details, err := ac.ApplicationService.GetForReview(c.Param("id"), c.Param("applicationId"), userID, role)
+	if err != nil {
+		return err
+	}
+
+	return utils.SendResponse(c, http.StatusOK, "Application retrieved successfully", details)
+}
+
+// ApplicantResumeHandler sends the resume of the applicant behind an application of a job
+func (ac *ApplicationController) ApplicantResumeHandler(c echo.Context) error {
+	userID, _ := c.Get("userID").(string)
+	role, _ := c.Get("role").(string)
+	resume, content, err := ac.ApplicationService.OpenApplicantResume(c.Param("id"), c.Param("applicationId"), userID, role)
+	if err != nil {
+		return err
+	}
+	return sendResume(c, resume, content)
+}
+
+// UpdateApplicationStatusHandler moves an application along the status pipeline
+func (ac *ApplicationController) UpdateApplicationStatusHandler(c echo.Context) error {
+	var body struct {
+		Status string `json:"status" validate:"required,oneof=screening interview offer rejected"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input").SetInternal(err)
+	}
+	if err := c.Validate(&body); err != nil {
+		return err // Reported per field by the custom error handler
+	}
+
+	userID, _ := c.Get("userID").(string)
+	role, _ := c.Get("role").(string)
+	application, err := ac.ApplicationService.UpdateStatus(c.Param("id"), c.Param("applicationId"), body.Status, userID, role)
+	if err != nil {
+		return err
+	}
+
+	return utils.SendResponse(c, http.StatusOK, "Application status updated successfully", application)
+}
+
+// MyApplicationsHandler lists the current user's applications
+func (ac *ApplicationController) MyApplicationsHandler(c echo.Context) error {
+	userID, _ := c.Get("userID").(string)
+	applications, err := ac.ApplicationService.ListByUser(userID)
+	if err != nil {
+		return err
+	}
+
+	return utils.SendResponse(c, http.StatusOK, "Applications retrieved successfully", applications)
+}
+
+// WithdrawApplicationHandler withdraws one of the current user's applications
+func (ac *ApplicationController) WithdrawApplicationHandler(c echo.Context) error {
+	userID, _ := c.Get("userID").(string)
+	application, err := ac.ApplicationService.Withdraw(c.Param("id"), userID)
+	if err != nil {
+		return err
+	}
+
+	return utils.SendResponse(c, http.StatusOK, "Application withdrawn successfully", application)
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"job-portal/models"
+	"job-portal/services"
+	"job-portal/utils"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CompanyController struct {
+	CompanyService *services.CompanyService
+}
+
+func NewCompanyController(companyService *services.CompanyService) *CompanyController {
+	return &CompanyController{CompanyService: companyService}
+}
+
+// ListCompaniesHandler lists companies by name, a page at a time
+func (cc *CompanyController) ListCompaniesHandler(c echo.Context) error {
+	opts, err := listOptions(c)
+	if err != nil {
+		return err
+	}
+	companies, pagination, err := cc.CompanyService.ListCompanies(opts.Page, opts.PageSize)
+	if err != nil {
+		return err
+	}
+
+	response := map[string]interface{}{
+		"status":  http.StatusOK,
+		"message": "Companies retrieved successfully",
+		"data": map[string]interface{}{
+			"companies": companies,
+		},
+	}
+	for key, value := range pagination {
+		response[key] = value
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// CreateCompanyHandler creates a company owned by the current user and shared with their active organization
+func (cc *CompanyController) CreateCompanyHandler(c echo.Context) error {
+	var company models.Company
+	if err := c.Bind(&company); err != nil {
+		return err
+	}
+	if err := c.Validate(&company); err != nil {
+		return err
+	}
+
+	userID, _ := c.Get("userID").(string)
+	orgID, _ := c.Get("orgID").(string)
+	if err := cc.CompanyService.CreateCompany(&company, userID, orgID); err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusCreated, "Company created successfully", company)
+}
+
+// GetCompanyHandler returns a company with a page of its open jobs
+func (cc *CompanyController) GetCompanyHandler(c echo.Context) error {
+	company, err := cc.CompanyService.GetCompany(c.Param("slug"))
+	if err != nil {
+		return err
+	}
+	opts, err := listOptions(c)
+	if err != nil {
+		return err
+	}
+	jobs, pagination, err := cc.CompanyService.CompanyJobs(company, opts)
+	if err != nil {
+		return err
+	}
+
+	response := jobListResponse(jobs, pagination)
+	response["message"] = "Company retrieved successfully"
+	response["data"] = map[string]interface{}{
+		"company": company,
+		"jobs":    jobs,
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// UpdateCompanyHandler replaces a company's details
+func (cc *CompanyController) UpdateCompanyHandler(c echo.Context) error {
+	var update models.Company
+	if err := c.Bind(&update); err != nil {
+		return err
+	}
+	if err := c.Validate(&update); err != nil {
+		return err
+	}
+
+	userID, _ := c.Get("userID").(string)
+	role, _ := c.Get("role").(string)
+	company, err := cc.CompanyService.UpdateCompany(c.Param("slug"), userID, role, &update)
+	if err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "Company updated successfully", company)
+}
+
+// SetVerifiedHandler lets an admin mark a company as verified or not with {"verified": true}
+func (cc *CompanyController) SetVerifiedHandler(c echo.Context) error {
+	var body struct {
+		Verified *bool `json:"verified" validate:"required"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return err
+	}
+	if err := c.Validate(&body); err != nil {
+		return err
+	}
+
+	company, err := cc.CompanyService.SetVerified(c.Param("slug"), *body.Verified)
+	if err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "Company verification updated", company)
+}
+
+// DeleteCompanyHandler deletes a company without jobs
+func (cc *CompanyController) DeleteCompanyHandler(c echo.Context) error {
+	userID, _ := c.Get("userID").(string)
+	role, _ := c.Get("role").(string)
+	if err := cc.CompanyService.DeleteCompany(c.Param("slug"), userID, role); err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "Company deleted successfully", nil)
+}
@@ -1,14 +1,16 @@
 package controllers
 
 import (
+	"encoding/json"
 	"job-portal/models"
 	"job-portal/services"
 	"job-portal/utils"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
-	"go.mongodb.org/mongo-driver/bson"
 )
 
 type JobController struct {
@@ -30,7 +32,10 @@ func (jc *JobController) CreateJobHandler(c echo.Context) error {
 		return err // Validation errors are handled by the custom error handler
 	}
 
-	if err := jc.JobService.CreateJob(&job); err != nil {
+	userID, _ := c.Get("userID").(string)
+	role, _ := c.Get("role").(string)
+	orgID, _ := c.Get("orgID").(string) // Set by ActiveOrg when acting for an organization
+	if err := jc.JobService.CreateJob(&job, userID, role, orgID); err != nil {
 		return err // Pass business logic errors to the error handler
 	}
 
@@ -40,28 +45,52 @@ func (jc *JobController) CreateJobHandler(c echo.Context) error {
 // GetJobHandler retrieves a job by ID
 func (jc *JobController) GetJobHandler(c echo.Context) error {
 	id := c.Param("id")
-	job, err := jc.JobService.GetJob(id)
+	userID, _ := c.Get("userID").(string)
+	role, _ := c.Get("role").(string)
+	job, err := jc.JobService.GetVisibleJob(id, userID, role)
 	if err != nil {
 		return err // Pass errors to the custom error handler
 	}
 
+	// Let clients revalidate a cached copy cheaply
+	c.Response().Header().Set("ETag", utils.VersionETag(job.Version))
+	if ifNoneMatch := c.Request().Header.Get("If-None-Match"); ifNoneMatch != "" {
+		versions := utils.ParseVersionETags(ifNoneMatch, true)
+		if utils.MatchesVersion(versions, job.Version) {
+			return c.NoContent(http.StatusNotModified)
+		}
+	}
+
 	return utils.SendResponse(c, http.StatusOK, "Job retrieved successfully", job)
 }
 
-// UpdateJobHandler updates a job by ID
+// UpdateJobHandler applies a JSON Merge Patch (RFC 7396) to a job by ID
 func (jc *JobController) UpdateJobHandler(c echo.Context) error {
 	id := c.Param("id")
-	var updateData map[string]interface{}
 
-	if err := c.Bind(&updateData); err != nil {
-		return err // Pass binding errors to the custom error handler
+	// Merge patches use their own media type, which echo's binder does not know about
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	if !strings.HasPrefix(contentType, echo.MIMEApplicationJSON) && !strings.HasPrefix(contentType, "application/merge-patch+json") {
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "Use application/merge-patch+json or application/json")
+	}
+	var patch map[string]interface{}
+	if err := json.NewDecoder(c.Request().Body).Decode(&patch); err != nil || patch == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Patch must be a JSON object").SetInternal(err)
+	}
+
+	ifMatch, err := ifMatchVersions(c)
+	if err != nil {
+		return err
 	}
 
-	updatedJob, err := jc.JobService.UpdateJob(id, updateData)
+	userID, _ := c.Get("userID").(string)
+	role, _ := c.Get("role").(string)
+	updatedJob, err := jc.JobService.UpdateJob(id, userID, role, ifMatch, patch, c.Validate)
 	if err != nil {
 		return err // Pass service errors to the custom error handler
 	}
 
+	c.Response().Header().Set("ETag", utils.VersionETag(updatedJob.Version))
 	return utils.SendResponse(c, http.StatusOK, "Job updated successfully", updatedJob)
 }
 
@@ -69,7 +98,14 @@ func (jc *JobController) UpdateJobHandler(c echo.Context) error {
 func (jc *JobController) DeleteJobHandler(c echo.Context) error {
 	id := c.Param("id")
 
-	deletedJob, err := jc.JobService.DeleteJob(id)
+	ifMatch, err := ifMatchVersions(c)
+	if err != nil {
+		return err
+	}
+
+	userID, _ := c.Get("userID").(string)
+	role, _ := c.Get("role").(string)
+	deletedJob, err := jc.JobService.DeleteJob(id, userID, role, ifMatch)
 	if err != nil {
 		return err // Pass errors to the custom error handler
 	}
@@ -78,16 +114,173 @@ func (jc *JobController) DeleteJobHandler(c echo.Context) error {
 }
 
 
+// ifMatchVersions reads the job versions from the If-Match header, which
+// changes to a job must carry so they cannot overwrite edits the client never saw
+func ifMatchVersions(c echo.Context) ([]int64, error) {
+	header := c.Request().Header.Get("If-Match")
+	if header == "" {
+		return nil, echo.NewHTTPError(http.StatusPreconditionRequired, "If-Match header with the job's ETag is required")
+	}
+	return utils.ParseVersionETags(header, false), nil
+}
+
+// PublishJobHandler publishes a job, right away or at the posted_at time given in the body
+func (jc *JobController) PublishJobHandler(c echo.Context) error {
+	var body struct {
+		PostedAt time.Time `json:"posted_at"` // Optional; a future time schedules the job
+	}
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&body); err != nil {
+			return err // Pass binding errors to the custom error handler
+		}
+	}
+
+	userID, _ := c.Get("userID").(string)
+	role, _ := c.Get("role").(string)
+	job, err := jc.JobService.PublishJob(c.Param("id"), userID, role, body.PostedAt, optionalIfMatch(c))
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("ETag", utils.VersionETag(job.Version))
+	return utils.SendResponse(c, http.StatusOK, "Job published successfully", job)
+}
+
+// PauseJobHandler takes a published job out of listings
+func (jc *JobController) PauseJobHandler(c echo.Context) error {
+	userID, _ := c.Get("userID").(string)
+	role, _ := c.Get("role").(string)
+	job, err := jc.JobService.PauseJob(c.Param("id"), userID, role, optionalIfMatch(c))
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("ETag", utils.VersionETag(job.Version))
+	return utils.SendResponse(c, http.StatusOK, "Job paused successfully", job)
+}
+
+// CloseJobHandler stops a job from taking applications for good
+func (jc *JobController) CloseJobHandler(c echo.Context) error {
+	userID, _ := c.Get("userID").(string)
+	role, _ := c.Get("role").(string)
+	job, err := jc.JobService.CloseJob(c.Param("id"), userID, role, optionalIfMatch(c))
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("ETag", utils.VersionETag(job.Version))
+	return utils.SendResponse(c, http.StatusOK, "Job closed successfully", job)
+}
+
+// optionalIfMatch reads the If-Match header of a status change. Unlike edits,
+// status changes are safe without it, but a client that sends one gets it checked.
+func optionalIfMatch(c echo.Context) []int64 {
+	header := c.Request().Header.Get("If-Match")
+	if header == "" {
+		return nil
+	}
+	return utils.ParseVersionETags(header, false)
+}
+
+// MyJobsHandler lists the current user's jobs in every status, optionally filtered with ?status=.
+// When acting for an organization it lists the organization's jobs instead.
+func (jc *JobController) MyJobsHandler(c echo.Context) error {
+	status := c.QueryParam("status")
+	switch status {
+	case "", models.JobStatusDraft, models.JobStatusPublished, models.JobStatusPaused, models.JobStatusClosed, models.JobStatusExpired:
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "status must be one of: draft, published, paused, closed, expired")
+	}
+
+	opts, err := listOptions(c)
+	if err != nil {
+		return err
+	}
+	var jobs []models.Job
+	var pagination map[string]interface{}
+	if orgID, _ := c.Get("orgID").(string); orgID != "" {
+		jobs, pagination, err = jc.JobService.ListOrgJobs(orgID, status, opts)
+	} else {
+		userID, _ := c.Get("userID").(string)
+		jobs, pagination, err = jc.JobService.ListOwnJobs(userID, status, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, jobListResponse(jobs, pagination))
+}
+
 // ListJobsHandler handles the GET request for fetching job listings with filters and search
 func (jc *JobController) ListJobsHandler(c echo.Context) error {
 	// Parse query parameters
-	datePosted := c.QueryParam("datePosted")     // e.g., 'anytime' or a specific date range
-	jobType := c.QueryParam("jobType")           // e.g., 'full-time', 'part-time', etc.
-	salaryRange := c.QueryParam("salaryRange")   // e.g., '0-2.5k'
-	workLocation := c.QueryParam("workLocation") // 'on-site', 'remote', 'hybrid'
-	search := c.QueryParam("search")             // Search term (e.g., job title or description)
+	params := services.JobFilterParams{
+		DatePosted:   c.QueryParam("datePosted"),   // e.g., 'anytime' or 'last_7_days'
+		PostedAfter:  c.QueryParam("postedAfter"),  // e.g., '2024-05-01'
+		PostedBefore: c.QueryParam("postedBefore"), // e.g., '2024-05-31'
+		JobType:      c.QueryParam("jobType"),      // e.g., 'full-time,contract'
+		Experience:   c.QueryParam("experience"),   // e.g., 'mid,senior'
+		Education:    c.QueryParam("education"),    // e.g., 'bachelor'
+		WorkLocation: c.QueryParam("workLocation"), // e.g., 'remote,hybrid'
+		Skills:       c.QueryParam("skills"),       // e.g., 'go,kubernetes'
+		SkillsMatch:  c.QueryParam("skillsMatch"),  // 'any' or 'all' of the skills
+		Location:     c.QueryParam("location"),     // Part of the location, e.g. 'berlin'
+		Company:      c.QueryParam("company"),      // Part of the company name
+		SalaryRange:  c.QueryParam("salaryRange"),  // e.g., '50000-100000', '0-2.5k' or '150000+'
+		Currency:     c.QueryParam("currency"),     // Currency of salaryRange, e.g. 'EUR'
+		Period:       c.QueryParam("period"),       // Pay period of salaryRange, e.g. 'month'
+		Search:       c.QueryParam("search"),       // Search term (e.g., job title or description)
+		Near:         c.QueryParam("near"),         // lat,lng, e.g. '23.81,90.41'
+		RadiusKm:     c.QueryParam("radiusKm"),     // e.g., '25'
+		Remote:       c.QueryParam("remote"),       // 'include' or 'exclude' remote jobs outside the radius
+	}
+	facets := c.QueryParam("facets") // Counts to return, e.g. 'type,work_location,salary_bucket'
+
+	opts, err := listOptions(c)
+	if err != nil {
+		return err
+	}
+
+	// Parse the query parameters into a job filter
+	filter, err := services.ParseJobFilter(params, jc.JobService.Rates)
+	if err != nil {
+		return err // Malformed filters are reported as 400 by the custom error handler
+	}
+	facetNames, err := services.ParseFacets(facets)
+	if err != nil {
+		return err
+	}
+
+	// Fetch filtered jobs with pagination and search; the public only sees open jobs
+	jobs, pagination, err := jc.JobService.ListOpenJobs(filter, opts)
+	if err != nil {
+		return err // A bad sort or cursor is reported as 400 by the custom error handler
+	}
+
+	response := jobListResponse(jobs, pagination)
+
+	// Counts per filter value, for showing e.g. "Remote (42)" next to each option
+	if len(facetNames) > 0 {
+		counts, err := jc.JobService.OpenJobFacets(filter, facetNames)
+		if err != nil {
+			return err
+		}
+		response["facets"] = counts
+	}
+
+	// Send response
+	return c.JSON(http.StatusOK, response)
+}
+
+// listOptions reads the sorting and pagination query parameters, defaulting to
+// page 1 and 10 items per page. Totals are counted for page numbers unless
+// withTotal=false, and for cursors only with withTotal=true.
+func listOptions(c echo.Context) (services.ListOptions, error) {
+	opts := services.ListOptions{
+		Sort:   c.QueryParam("sort"),
+		Cursor: c.QueryParam("cursor"),
+	}
 
-	// Pagination (default to page 1 and 10 items per page)
 	page, err := strconv.Atoi(c.QueryParam("page"))
 	if err != nil || page <= 0 {
 		page = 1
@@ -96,44 +289,28 @@ func (jc *JobController) ListJobsHandler(c echo.Context) error {
 	if err != nil || pageSize <= 0 {
 		pageSize = 10
 	}
+	opts.Page, opts.PageSize = page, pageSize
 
-	// Apply filters to construct the query filter
-	// Here, we need to pass an additional bson.M for the existing filters
-	filter := bson.M{} // Initialize the filter as an empty bson.M
-	filter, err = services.ApplyFilters(datePosted, jobType, salaryRange, workLocation, filter)
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"status":  http.StatusInternalServerError,
-			"message": "Failed to apply filters",
-			"error":   err.Error(),
-		})
-	}
-
-	// Fetch filtered jobs with pagination and search
-	// Now passing all the necessary arguments to ListJobs
-	jobs, pagination, err := jc.JobService.ListJobs(filter, page, pageSize, search, datePosted, jobType, salaryRange, workLocation)
-	if err != nil {
-		// Handle error and return a custom error response
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"status":  http.StatusInternalServerError,
-			"message": "Failed to retrieve jobs",
-			"error":   err.Error(),
-		})
+	opts.WithTotal = opts.Cursor == ""
+	if withTotal := c.QueryParam("withTotal"); withTotal != "" {
+		if opts.WithTotal, err = strconv.ParseBool(withTotal); err != nil {
+			return opts, echo.NewHTTPError(http.StatusBadRequest, "withTotal must be true or false")
+		}
 	}
+	return opts, nil
+}
 
-	// Prepare response data with pagination
+// jobListResponse prepares a page of jobs together with its pagination data
+func jobListResponse(jobs []models.Job, pagination map[string]interface{}) map[string]interface{} {
 	response := map[string]interface{}{
-		"status":      http.StatusOK,
-		"message":     "Jobs retrieved successfully",
-		"totalItems":  pagination["totalItems"],
-		"totalPages":  pagination["totalPages"],
-		"currentPage": pagination["currentPage"],
-		"pageSize":    pagination["pageSize"],
+		"status":  http.StatusOK,
+		"message": "Jobs retrieved successfully",
 		"data": map[string]interface{}{
 			"jobs": jobs,
 		},
 	}
-
-	// Send response
-	return c.JSON(http.StatusOK, response)
+	for key, value := range pagination {
+		response[key] = value
+	}
+	return response
 }
@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"job-portal/token"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type KeyController struct {
+	JWT *token.Manager
+}
+
+func NewKeyController(jwt *token.Manager) *KeyController {
+	return &KeyController{JWT: jwt}
+}
+
+// JWKSHandler publishes the public keys that portal access tokens can be verified with
+func (kc *KeyController) JWKSHandler(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, kc.JWT.JWKS())
+}
@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"job-portal/models"
+	"job-portal/services"
+	"job-portal/utils"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type OrganizationController struct {
+	OrganizationService *services.OrganizationService
+}
+
+func NewOrganizationController(organizationService *services.OrganizationService) *OrganizationController {
+	return &OrganizationController{OrganizationService: organizationService}
+}
+
+// CreateOrganizationHandler creates an organization owned by the current user
+func (oc *OrganizationController) CreateOrganizationHandler(c echo.Context) error {
+	var org models.Organization
+	if err := c.Bind(&org); err != nil {
+		return err
+	}
+	if err := c.Validate(&org); err != nil {
+		return err
+	}
+
+	userID, _ := c.Get("userID").(string)
+	if err := oc.OrganizationService.CreateOrganization(&org, userID); err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusCreated, "Organization created successfully", org)
+}
+
+// MyOrganizationsHandler lists the organizations the current user belongs to, with their role
+func (oc *OrganizationController) MyOrganizationsHandler(c echo.Context) error {
+	userID, _ := c.Get("userID").(string)
+	orgs, err := oc.OrganizationService.ListUserOrganizations(userID)
+	if err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "Organizations retrieved successfully", orgs)
+}
+
+// GetOrganizationHandler retrieves an organization and its members
+func (oc *OrganizationController) GetOrganizationHandler(c echo.Context) error {
+	org, err := oc.OrganizationService.GetOrganization(c.Param("orgId"))
+	if err != nil {
+		return err
+	}
+	members, err := oc.OrganizationService.ListMembers(c.Param("orgId"))
+	if err != nil {
+		return err
+	}
+	orgRole, _ := c.Get("orgRole").(string)
+	return utils.SendResponse(c, http.StatusOK, "Organization retrieved successfully", map[string]interface{}{
+		"organization": org,
+		"members":      members,
+		"role":         orgRole,
+	})
+}
+
+// RenameOrganizationHandler changes the name of an organization
+func (oc *OrganizationController) RenameOrganizationHandler(c echo.Context) error {
+	var body struct {
+		Name string `json:"name" validate:"required,max=100"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return err
+	}
+	if err := c.Validate(&body); err != nil {
+		return err
+	}
+
+	org, err := oc.OrganizationService.RenameOrganization(c.Param("orgId"), body.Name)
+	if err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "Organization updated successfully", org)
+}
+
+// DeleteOrganizationHandler deletes an organization without jobs
+func (oc *OrganizationController) DeleteOrganizationHandler(c echo.Context) error {
+	if err := oc.OrganizationService.DeleteOrganization(c.Param("orgId")); err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "Organization deleted successfully", nil)
+}
+
+// ChangeMemberRoleHandler gives a member another role with {"role": "admin"}; "owner" transfers the ownership
+func (oc *OrganizationController) ChangeMemberRoleHandler(c echo.Context) error {
+	var body struct {
+		Role string `json:"role" validate:"required,oneof=owner admin member"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return err
+	}
+	if err := c.Validate(&body); err != nil {
+		return err
+	}
+
+	userID, _ := c.Get("userID").(string)
+	member, err := oc.OrganizationService.ChangeMemberRole(c.Param("orgId"), userID, c.Param("userId"), body.Role)
+	if err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "Member role updated successfully", member)
+}
+
+// RemoveMemberHandler removes a member from an organization, or lets the current user leave it
+func (oc *OrganizationController) RemoveMemberHandler(c echo.Context) error {
+	userID, _ := c.Get("userID").(string)
+	orgRole, _ := c.Get("orgRole").(string)
+	if err := oc.OrganizationService.RemoveMember(c.Param("orgId"), userID, orgRole, c.Param("userId")); err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "Member removed successfully", nil)
+}
+
+// InviteHandler emails an invitation to join the organization
+func (oc *OrganizationController) InviteHandler(c echo.Context) error {
+	var invitation models.Invitation
+	if err := c.Bind(&invitation); err != nil {
+		return err
+	}
+	if err := c.Validate(&invitation); err != nil {
+		return err
+	}
+
+	userID, _ := c.Get("userID").(string)
+	orgRole, _ := c.Get("orgRole").(string)
+	if err := oc.OrganizationService.Invite(c.Param("orgId"), userID, orgRole, &invitation); err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusCreated, "Invitation sent", invitation)
+}
+
+// ListInvitationsHandler lists the pending invitations of an organization
+func (oc *OrganizationController) ListInvitationsHandler(c echo.Context) error {
+	invitations, err := oc.OrganizationService.ListInvitations(c.Param("orgId"))
+	if err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "Invitations retrieved successfully", invitations)
+}
+
+// RevokeInvitationHandler cancels a pending invitation
+func (oc *OrganizationController) RevokeInvitationHandler(c echo.Context) error {
+	if err := oc.OrganizationService.RevokeInvitation(c.Param("orgId"), c.Param("invitationId")); err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "Invitation revoked", nil)
+}
+
+// AcceptInvitationHandler makes the current user a member with the emailed {"token": "..."}
+func (oc *OrganizationController) AcceptInvitationHandler(c echo.Context) error {
+	var body struct {
+		Token string `json:"token" validate:"required"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return err
+	}
+	if err := c.Validate(&body); err != nil {
+		return err
+	}
+
+	userID, _ := c.Get("userID").(string)
+	email, _ := c.Get("email").(string)
+	membership, err := oc.OrganizationService.AcceptInvitation(body.Token, userID, email)
+	if err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "Invitation accepted", membership)
+}
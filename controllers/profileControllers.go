@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"io"
+	"job-portal/models"
+	"job-portal/services"
+	"job-portal/utils"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ProfileController struct {
+	ProfileService *services.ProfileService
+}
+
+func NewProfileController(profileService *services.ProfileService) *ProfileController {
+	return &ProfileController{ProfileService: profileService}
+}
+
+// GetProfileHandler retrieves the current user's profile
+func (pc *ProfileController) GetProfileHandler(c echo.Context) error {
+	userID, _ := c.Get("userID").(string)
+	profile, err := pc.ProfileService.GetProfile(userID)
+	if err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "Profile retrieved successfully", profile)
+}
+
+// UpdateProfileHandler replaces the current user's profile
+func (pc *ProfileController) UpdateProfileHandler(c echo.Context) error {
+	var profile models.Profile
+	if err := c.Bind(&profile); err != nil {
+		return err
+	}
+	if err := c.Validate(&profile); err != nil {
+		return err
+	}
+
+	userID, _ := c.Get("userID").(string)
+	updated, err := pc.ProfileService.UpdateProfile(userID, &profile)
+	if err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "Profile updated successfully", updated)
+}
+
+// UploadResumeHandler stores the resume sent as the "resume" field of a multipart form
+func (pc *ProfileController) UploadResumeHandler(c echo.Context) error {
+	header, err := c.FormFile("resume")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "A multipart form with a resume file is required").SetInternal(err)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	userID, _ := c.Get("userID").(string)
+	profile, err := pc.ProfileService.UploadResume(userID, header.Filename, file)
+	if err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "Resume uploaded successfully", profile)
+}
+
+// DownloadResumeHandler sends the current user's resume
+func (pc *ProfileController) DownloadResumeHandler(c echo.Context) error {
+	userID, _ := c.Get("userID").(string)
+	resume, content, err := pc.ProfileService.OpenResume(userID)
+	if err != nil {
+		return err
+	}
+	return sendResume(c, resume, content)
+}
+
+// sendResume streams a resume as a download under its original file name
+func sendResume(c echo.Context, resume *models.Resume, content io.ReadCloser) error {
+	defer content.Close()
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": resume.FileName}))
+	header.Set(echo.HeaderContentLength, strconv.FormatInt(resume.Size, 10))
+	header.Set(echo.HeaderXContentTypeOptions, "nosniff") // Never render an uploaded file inline
+	return c.Stream(http.StatusOK, resume.ContentType, content)
+}
@@ -1,11 +1,11 @@
 package controllers
 
 import (
-	"bytes"
-	"fmt"
-	"io"
+	"errors"
+	"job-portal/middlewares"
 	"job-portal/models"
 	"job-portal/services"
+	"job-portal/token"
 	"job-portal/utils"
 	"net/http"
 	"time"
@@ -14,43 +14,33 @@ import (
 )
 
 type UserController struct {
-	UserService *services.UserService
+	UserService  *services.UserService
+	CookieSecure bool                   // Send auth cookies over HTTPS only
+	CSRF         middlewares.CSRFConfig // Protects the refresh cookie
 }
 
-func NewUserController(userService *services.UserService) *UserController {
-	return &UserController{UserService: userService}
+func NewUserController(userService *services.UserService, cookieSecure bool, csrf middlewares.CSRFConfig) *UserController {
+	return &UserController{UserService: userService, CookieSecure: cookieSecure, CSRF: csrf}
 }
 
 func (uc *UserController) Register(c echo.Context) error {
-	var user models.User
-
-	// Read and log the raw request body for debugging
-	body, err := io.ReadAll(c.Request().Body)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to read request body").SetInternal(err)
+	// The user model never binds a password from JSON, so registration has its own input
+	var body struct {
+		Name     string `json:"name" validate:"required"`
+		Email    string `json:"email" validate:"required,email"`
+		Password string `json:"password" validate:"required,min=8"`
 	}
-	fmt.Println("Request Body:", string(body))
-
-	// Reset the request body so it can be read again by c.Bind
-	c.Request().Body = io.NopCloser(bytes.NewBuffer(body))
-
-	// Bind the request body to the user struct
-	if err := c.Bind(&user); err != nil {
+	if err := c.Bind(&body); err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input").SetInternal(err)
 	}
-	// Set default role if not provided
-	if user.Role == "" {
-		user.Role = "user"
-	}
-	// Validate the user struct
-	if err := c.Validate(&user); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Validation failed").SetInternal(err)
+	if err := c.Validate(&body); err != nil {
+		return err // Reported per field by the custom error handler
 	}
 
 	// Register the user
-	err = uc.UserService.Register(&user)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusConflict, "Failed to register user").SetInternal(err)
+	user := models.User{Name: body.Name, Email: body.Email, Password: body.Password}
+	if err := uc.UserService.Register(&user); err != nil {
+		return err
 	}
 
 	// Return success response with user details
@@ -68,27 +58,224 @@ func (uc *UserController) Login(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input").SetInternal(err)
 	}
 	if err := c.Validate(&credentials); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Validation failed").SetInternal(err)
+		return err // Reported per field by the custom error handler
+	}
+
+	// Authenticate the user and get tokens
+	tokens, user, err := uc.UserService.Authenticate(credentials.Email, credentials.Password, c.Request().UserAgent(), c.RealIP())
+	if err != nil {
+		return err
+	}
+
+	// On successful authentication, set the tokens as HttpOnly cookies
+	csrfToken, err := uc.setAuthCookies(c, tokens)
+	if err != nil {
+		return err
+	}
+
+	// Return the response using SendResponse
+	loginResponse := utils.CreateLoginResponse(tokens.AccessToken, tokens.RefreshToken, user.ID.Hex(), user.Email, user.Role)
+	loginResponse.Data.CSRFToken = csrfToken
+	return c.JSON(http.StatusOK, loginResponse)
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token
+func (uc *UserController) Refresh(c echo.Context) error {
+	var body struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input").SetInternal(err)
+	}
+
+	// Fall back to the cookie set at login, which needs the same CSRF protection as the auth cookie
+	if body.RefreshToken == "" {
+		if cookie, err := c.Cookie("refresh_token"); err == nil && cookie.Value != "" {
+			if err := middlewares.VerifyCSRF(c, uc.CSRF); err != nil {
+				return err
+			}
+			body.RefreshToken = cookie.Value
+		}
+	}
+	if body.RefreshToken == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Refresh token is required")
+	}
+
+	tokens, user, err := uc.UserService.Refresh(body.RefreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+			uc.clearAuthCookies(c)
+		}
+		return err
+	}
+
+	csrfToken, err := uc.setAuthCookies(c, tokens)
+	if err != nil {
+		return err
+	}
+
+	loginResponse := utils.CreateLoginResponse(tokens.AccessToken, tokens.RefreshToken, user.ID.Hex(), user.Email, user.Role)
+	loginResponse.Data.CSRFToken = csrfToken
+	loginResponse.Message = "Token refreshed successfully"
+	return c.JSON(http.StatusOK, loginResponse)
+}
+
+// Logout revokes the session of the current access token
+func (uc *UserController) Logout(c echo.Context) error {
+	sessionID, _ := c.Get("sessionID").(string)
+	if err := uc.UserService.Logout(sessionID); err != nil {
+		return err
+	}
+
+	uc.clearAuthCookies(c)
+	return utils.SendResponse(c, http.StatusOK, "Logged out successfully", nil)
+}
+
+// LogoutAll revokes every session of the current user
+func (uc *UserController) LogoutAll(c echo.Context) error {
+	userID, _ := c.Get("userID").(string)
+	if err := uc.UserService.LogoutAll(userID); err != nil {
+		return err
+	}
+
+	uc.clearAuthCookies(c)
+	return utils.SendResponse(c, http.StatusOK, "Logged out of all sessions successfully", nil)
+}
+
+// SetRoleHandler lets a user manager change a user's role with {"role": "recruiter"}
+func (uc *UserController) SetRoleHandler(c echo.Context) error {
+	var body struct {
+		Role string `json:"role" validate:"required,oneof=admin user recruiter"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return err
 	}
+	if err := c.Validate(&body); err != nil {
+		return err
+	}
+
+	user, err := uc.UserService.SetRole(c.Param("id"), body.Role)
+	if err != nil {
+		return err
+	}
+	return utils.SendResponse(c, http.StatusOK, "User role updated successfully", user)
+}
+
+// VerifyEmail confirms the email address using the token from the verification link
+func (uc *UserController) VerifyEmail(c echo.Context) error {
+	token := c.QueryParam("token")
+	if token == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "Token is required")
+	}
+
+	user, err := uc.UserService.VerifyEmail(token)
+	if err != nil {
+		return err
+	}
+
+	return utils.SendResponse(c, http.StatusOK, "Email verified successfully", user)
+}
+
+// ResendVerification sends a new verification link to the current user
+func (uc *UserController) ResendVerification(c echo.Context) error {
+	userID, _ := c.Get("userID").(string)
+	if err := uc.UserService.ResendVerification(userID); err != nil {
+		return err
+	}
+
+	return utils.SendResponse(c, http.StatusOK, "Verification email sent", nil)
+}
+
+// ForgotPassword sends a password reset link to the given email
+func (uc *UserController) ForgotPassword(c echo.Context) error {
+	var body struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input").SetInternal(err)
+	}
+	if err := c.Validate(&body); err != nil {
+		return err // Reported per field by the custom error handler
+	}
+
+	if err := uc.UserService.ForgotPassword(body.Email); err != nil {
+		return err
+	}
+
+	// Same response whether or not the account exists
+	return utils.SendResponse(c, http.StatusOK, "If an account exists for this email, a reset link has been sent", nil)
+}
+
+// ResetPassword sets a new password using the token from the reset link
+func (uc *UserController) ResetPassword(c echo.Context) error {
+	var body struct {
+		Token    string `json:"token" validate:"required"`
+		Password string `json:"password" validate:"required,min=8"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input").SetInternal(err)
+	}
+	if err := c.Validate(&body); err != nil {
+		return err // Reported per field by the custom error handler
+	}
+
+	if err := uc.UserService.ResetPassword(body.Token, body.Password); err != nil {
+		return err
+	}
+
+	uc.clearAuthCookies(c)
+	return utils.SendResponse(c, http.StatusOK, "Password reset successfully", nil)
+}
 
-	// Authenticate the user and get token
-	token, user, err := uc.UserService.Authenticate(credentials.Email, credentials.Password)
+// setAuthCookies stores the access and refresh tokens as HttpOnly cookies, together
+// with a fresh CSRF token for double-submit protection, which it returns
+func (uc *UserController) setAuthCookies(c echo.Context, tokens *services.TokenPair) (string, error) {
+	csrfToken, err := utils.GenerateOpaqueToken()
 	if err != nil {
-		return echo.NewHTTPError(http.StatusUnauthorized, "Invalid email or password").SetInternal(err)
+		return "", err
 	}
 
-	// On successful authentication, set the access token as HttpOnly cookie
 	c.SetCookie(&http.Cookie{
 		Name:     "auth_token",
-		Value:    token,
-		HttpOnly: true,  // Ensure cookie is not accessible via JavaScript
-		Secure:   false, // Set to true for production (HTTPS)
+		Value:    tokens.AccessToken,
+		HttpOnly: true, // Ensure cookie is not accessible via JavaScript
+		Secure:   uc.CookieSecure,
+		SameSite: http.SameSiteNoneMode,
+		Expires:  time.Now().Add(token.AccessTokenTTL), // Same lifetime as the token itself
+		Path:     "/",                                  // Available for all routes
+	})
+	c.SetCookie(&http.Cookie{
+		Name:     "refresh_token",
+		Value:    tokens.RefreshToken,
+		HttpOnly: true,
+		Secure:   uc.CookieSecure,
 		SameSite: http.SameSiteNoneMode,
-		Expires:  time.Now().Add(24 * time.Hour), // 1 day expiration
-		Path:     "/",                            // Available for all routes
+		Expires:  time.Now().Add(token.RefreshTokenTTL),
+		Path:     "/auth", // Only sent to the refresh endpoint
 	})
+	c.SetCookie(&http.Cookie{
+		Name:     middlewares.CSRFCookieName,
+		Value:    csrfToken,
+		HttpOnly: false, // Read by the frontend and echoed in the X-CSRF-Token header
+		Secure:   uc.CookieSecure,
+		SameSite: http.SameSiteNoneMode,
+		Expires:  time.Now().Add(token.RefreshTokenTTL),
+		Path:     "/",
+	})
+	return csrfToken, nil
+}
 
-	// Return the response using SendResponse
-	loginResponse := utils.CreateLoginResponse(token, user.ID.Hex(), user.Email, user.Role)
-	return c.JSON(http.StatusOK, loginResponse)
+// clearAuthCookies expires the cookies set by setAuthCookies
+func (uc *UserController) clearAuthCookies(c echo.Context) {
+	for name, path := range map[string]string{"auth_token": "/", "refresh_token": "/auth", middlewares.CSRFCookieName: "/"} {
+		c.SetCookie(&http.Cookie{
+			Name:     name,
+			Value:    "",
+			HttpOnly: true,
+			Secure:   uc.CookieSecure,
+			SameSite: http.SameSiteNoneMode,
+			MaxAge:   -1,
+			Path:     path,
+		})
+	}
 }
@@ -0,0 +1,101 @@
+package currency
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+)
+
+// defaultTable is the exchange-rate table used when no file is configured
+//
+//go:embed rates.json
+var defaultTable []byte
+
+var codePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Rates is an exchange-rate table that converts amounts into its base currency.
+// It is read once at startup, so rates are only as current as the table.
+type Rates struct {
+	Base  string
+	rates map[string]float64 // Units of Base per unit of each currency, Base included
+}
+
+// table is the JSON form of Rates: {"base": "USD", "rates": {"EUR": 1.08, ...}}
+type table struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// Default returns the built-in exchange-rate table
+func Default() *Rates {
+	rates, err := Parse(defaultTable)
+	if err != nil {
+		panic("currency: invalid built-in rates: " + err.Error())
+	}
+	return rates
+}
+
+// Load reads an exchange-rate table from a JSON file, or returns the built-in
+// table when path is empty
+func Load(path string) (*Rates, error) {
+	if path == "" {
+		return Default(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse reads an exchange-rate table. Every rate is the value of one unit of
+// the currency in the base currency.
+func Parse(data []byte) (*Rates, error) {
+	var t table
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid exchange-rate table: %w", err)
+	}
+	if !codePattern.MatchString(t.Base) {
+		return nil, fmt.Errorf("invalid base currency %q", t.Base)
+	}
+
+	rates := &Rates{Base: t.Base, rates: map[string]float64{t.Base: 1}}
+	for code, rate := range t.Rates {
+		if !codePattern.MatchString(code) {
+			return nil, fmt.Errorf("invalid currency code %q", code)
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("rate of %s must be positive", code)
+		}
+		if code == t.Base && rate != 1 {
+			return nil, fmt.Errorf("rate of the base currency %s must be 1", code)
+		}
+		rates.rates[code] = rate
+	}
+	return rates, nil
+}
+
+// Rate returns the value of one unit of the currency in the base currency
+func (r *Rates) Rate(code string) (float64, bool) {
+	rate, ok := r.rates[code]
+	return rate, ok
+}
+
+// ToBase converts an amount of the currency into the base currency
+func (r *Rates) ToBase(amount float64, code string) (float64, bool) {
+	rate, ok := r.rates[code]
+	return amount * rate, ok
+}
+
+// Currencies lists the codes of every currency in the table, sorted
+func (r *Rates) Currencies() []string {
+	codes := make([]string, 0, len(r.rates))
+	for code := range r.rates {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -0,0 +1,142 @@
+package geo
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultPlaces is the gazetteer used when no file is configured
+//
+//go:embed gazetteer.json
+var defaultPlaces []byte
+
+var _ Geocoder = (*Gazetteer)(nil)
+
+// Place is one entry of a gazetteer file
+type Place struct {
+	Name    string   `json:"name"`
+	Country string   `json:"country"`
+	Lat     float64  `json:"lat"`
+	Lng     float64  `json:"lng"`
+	Aliases []string `json:"aliases"`
+}
+
+// Gazetteer is an offline Geocoder that looks places up by name in a fixed list
+type Gazetteer struct {
+	places    map[string][]gazetteerPlace // Keyed by lower-cased name and alias
+	countries map[string]string           // Lower-cased country name or alias to country name
+}
+
+type gazetteerPlace struct {
+	point   Point
+	country string // Lower-cased
+}
+
+// countryAliases are the common short names of the countries in a gazetteer
+var countryAliases = map[string]string{
+	"usa":                      "united states",
+	"us":                       "united states",
+	"united states of america": "united states",
+	"uk":                       "united kingdom",
+	"great britain":            "united kingdom",
+	"england":                  "united kingdom",
+	"scotland":                 "united kingdom",
+	"wales":                    "united kingdom",
+	"uae":                      "united arab emirates",
+	"korea":                    "south korea",
+	"holland":                  "netherlands",
+}
+
+// DefaultGazetteer returns the built-in gazetteer of major cities
+func DefaultGazetteer() *Gazetteer {
+	gazetteer, err := ParseGazetteer(defaultPlaces)
+	if err != nil {
+		panic("geo: invalid built-in gazetteer: " + err.Error())
+	}
+	return gazetteer
+}
+
+// LoadGazetteer reads a gazetteer from a JSON file of places, or returns the
+// built-in gazetteer when path is empty
+func LoadGazetteer(path string) (*Gazetteer, error) {
+	if path == "" {
+		return DefaultGazetteer(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGazetteer(data)
+}
+
+// ParseGazetteer reads a JSON array of places
+func ParseGazetteer(data []byte) (*Gazetteer, error) {
+	var places []Place
+	if err := json.Unmarshal(data, &places); err != nil {
+		return nil, fmt.Errorf("invalid gazetteer: %w", err)
+	}
+
+	g := &Gazetteer{places: map[string][]gazetteerPlace{}, countries: map[string]string{}}
+	for _, place := range places {
+		if place.Name == "" || place.Lat < -90 || place.Lat > 90 || place.Lng < -180 || place.Lng > 180 {
+			return nil, fmt.Errorf("invalid gazetteer place %q", place.Name)
+		}
+		entry := gazetteerPlace{point: Point{Lat: place.Lat, Lng: place.Lng}, country: normalizeName(place.Country)}
+		for _, name := range append([]string{place.Name}, place.Aliases...) {
+			key := normalizeName(name)
+			g.places[key] = append(g.places[key], entry)
+		}
+		if entry.country != "" {
+			g.countries[entry.country] = entry.country
+		}
+	}
+	for alias, country := range countryAliases {
+		if _, ok := g.countries[country]; ok {
+			g.countries[alias] = country
+		}
+	}
+	return g, nil
+}
+
+// Geocode finds the most specific comma separated part of the location that
+// names a place, so "Gulshan, Dhaka, Bangladesh" finds Dhaka. A last part that
+// names a country restricts the match to that country. Any other last part
+// that is not a known place, such as a state, is a qualifier the gazetteer
+// cannot check, so "Paris, Texas" is not found rather than put in France.
+func (g *Gazetteer) Geocode(ctx context.Context, location string) (Point, error) {
+	var parts []string
+	for _, part := range strings.Split(location, ",") {
+		if part = normalizeName(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return Point{}, ErrNotFound
+	}
+
+	country := ""
+	if last := parts[len(parts)-1]; len(parts) > 1 {
+		if name, ok := g.countries[last]; ok {
+			country, parts = name, parts[:len(parts)-1]
+		} else if _, ok := g.places[last]; !ok {
+			return Point{}, ErrNotFound
+		}
+	}
+
+	for _, part := range parts {
+		for _, place := range g.places[part] {
+			if country == "" || place.country == country {
+				return place.point, nil
+			}
+		}
+	}
+	return Point{}, ErrNotFound
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
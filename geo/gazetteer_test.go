@@ -0,0 +1,58 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGazetteerGeocode(t *testing.T) {
+	g, err := ParseGazetteer([]byte(`[
+		{"name": "Dhaka", "country": "Bangladesh", "lat": 23.81, "lng": 90.41, "aliases": ["Dacca"]},
+		{"name": "Paris", "country": "France", "lat": 48.86, "lng": 2.35},
+		{"name": "London", "country": "United Kingdom", "lat": 51.51, "lng": -0.13},
+		{"name": "London", "country": "Canada", "lat": 42.98, "lng": -81.25},
+		{"name": "Singapore", "country": "Singapore", "lat": 1.35, "lng": 103.82}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dhaka := Point{Lat: 23.81, Lng: 90.41}
+	tests := []struct {
+		location string
+		want     Point
+		found    bool
+	}{
+		{"Dhaka", dhaka, true},
+		{"  dacca ", dhaka, true},
+		{"Dhaka, Bangladesh", dhaka, true},
+		{"Gulshan, Dhaka, Bangladesh", dhaka, true},
+		{"Gulshan, Dhaka", dhaka, true},
+		{"Paris, France", Point{Lat: 48.86, Lng: 2.35}, true},
+		{"Paris, Texas", Point{}, false},
+		{"Paris, United States", Point{}, false},
+		{"Paris, USA", Point{}, false},
+		{"London", Point{Lat: 51.51, Lng: -0.13}, true}, // The first listed wins without a country
+		{"London, UK", Point{Lat: 51.51, Lng: -0.13}, true},
+		{"London, Canada", Point{Lat: 42.98, Lng: -81.25}, true},
+		{"Singapore", Point{Lat: 1.35, Lng: 103.82}, true},
+		{"Singapore, Singapore", Point{Lat: 1.35, Lng: 103.82}, true},
+		{"Bangladesh", Point{}, false},
+		{"Atlantis", Point{}, false},
+		{" , ", Point{}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := g.Geocode(context.Background(), tt.location)
+		if !tt.found {
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("Geocode(%q) = %v, %v; want ErrNotFound", tt.location, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Geocode(%q) = %v, %v; want %v", tt.location, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package geo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EarthRadiusKm is the mean radius of the Earth, used for every distance
+const EarthRadiusKm = 6371.0088
+
+// ErrNotFound is returned by a Geocoder for a location it does not know
+var ErrNotFound = errors.New("location not found")
+
+// Point is a position on the Earth in degrees
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Geocoder resolves free-text locations, such as "Dhaka, Bangladesh", to points
+type Geocoder interface {
+	Geocode(ctx context.Context, location string) (Point, error)
+}
+
+// DistanceKm returns the great-circle distance between two points
+func DistanceKm(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat, dLng := lat2-lat1, (b.Lng-a.Lng)*math.Pi/180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// ParsePoint reads "lat,lng" in degrees
+func ParsePoint(value string) (Point, error) {
+	latPart, lngPart, ok := strings.Cut(value, ",")
+	if !ok {
+		return Point{}, fmt.Errorf("must look like 23.81,90.41 (latitude,longitude), got %q", value)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latPart), 64)
+	if err != nil || math.IsNaN(lat) || lat < -90 || lat > 90 {
+		return Point{}, fmt.Errorf("latitude must be a number between -90 and 90, got %q", latPart)
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(lngPart), 64)
+	if err != nil || math.IsNaN(lng) || lng < -180 || lng > 180 {
+		return Point{}, fmt.Errorf("longitude must be a number between -180 and 180, got %q", lngPart)
+	}
+	return Point{Lat: lat, Lng: lng}, nil
+}
@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// LogMailer logs every message's recipient and subject and, if Dir is set, writes
+// the whole message to a file in Dir. It lets the whole email flow be exercised offline.
+type LogMailer struct {
+	Dir string
+}
+
+// NewLogMailer creates a LogMailer that also stores messages in dir (leave empty to only log)
+func NewLogMailer(dir string) *LogMailer {
+	return &LogMailer{Dir: dir}
+}
+
+// Send logs the recipient and subject and writes the message to Dir as an .eml file
+func (m *LogMailer) Send(msg Message) error {
+	// The body carries single-use tokens, so only the file keeps it
+	log.Printf("mail to=%s subject=%q", msg.To, msg.Subject)
+
+	if m.Dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	content := fmt.Sprintf("To: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n", msg.To, msg.Subject, time.Now().Format(time.RFC1123Z), msg.Body)
+	return os.WriteFile(filepath.Join(m.Dir, name), []byte(content), 0o600)
+}
@@ -0,0 +1,14 @@
+package mailer
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails. Implementations can send through SMTP, a provider API,
+// or, like LogMailer, just record the message locally.
+type Mailer interface {
+	Send(msg Message) error
+}
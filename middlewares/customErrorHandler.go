@@ -1,12 +1,29 @@
 package middlewares
 
 import (
+	"errors"
 	"fmt"
+	"job-portal/apperrors"
 	"net/http"
+	"reflect"
+	"strings"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
 
+// domainStatus maps each kind of domain error to its HTTP status
+var domainStatus = map[error]int{
+	apperrors.ErrBadRequest:   http.StatusBadRequest,
+	apperrors.ErrInvalidID:    http.StatusBadRequest,
+	apperrors.ErrUnauthorized: http.StatusUnauthorized,
+	apperrors.ErrForbidden:    http.StatusForbidden,
+	apperrors.ErrNotFound:     http.StatusNotFound,
+	apperrors.ErrConflict:     http.StatusConflict,
+	apperrors.ErrValidation:   http.StatusUnprocessableEntity,
+	apperrors.ErrPrecondition: http.StatusPreconditionFailed,
+}
+
 // CustomHTTPErrorHandler handles errors globally for the application
 func CustomHTTPErrorHandler(err error, c echo.Context) {
 	if c.Response().Committed {
@@ -15,26 +32,43 @@ func CustomHTTPErrorHandler(err error, c echo.Context) {
 
 	code := http.StatusInternalServerError
 	message := "Internal Server Error"
-	var errors interface{} = nil
+	var details interface{} = nil
 
-	if he, ok := err.(*echo.HTTPError); ok {
+	var he *echo.HTTPError
+	var validationErrors validator.ValidationErrors
+	var domainErr *apperrors.Error
+	switch {
+	case errors.As(err, &he):
 		code = he.Code
 		if he.Message != nil {
 			message = fmt.Sprintf("%v", he.Message)
 		}
 		if he.Internal != nil {
-			errors = he.Internal.Error()
+			details = he.Internal.Error()
+		}
+	case errors.As(err, &validationErrors):
+		code = http.StatusUnprocessableEntity
+		message = "Validation failed"
+		details = fieldMessages(validationErrors)
+	case errors.As(err, &domainErr):
+		if status, ok := domainStatus[domainErr.Kind]; ok {
+			code = status
+		}
+		message = domainErr.Message
+		if len(domainErr.Fields) > 0 {
+			details = domainErr.Fields
 		}
 	}
 
 	c.Logger().Error(err)
 
-	if c.Request().Header.Get("Accept") == "application/json" || c.Request().Header.Get("Content-Type") == "application/json" {
+	// Any JSON flavour, e.g. application/merge-patch+json, gets a JSON error back
+	if strings.Contains(c.Request().Header.Get("Accept"), "json") || strings.Contains(c.Request().Header.Get("Content-Type"), "json") {
 		response := map[string]interface{}{
 			"success": false,
 			"status":  code,
 			"message": message,
-			"errors":  errors,
+			"errors":  details,
 		}
 		if err := c.JSON(code, response); err != nil {
 			c.Logger().Error(err)
@@ -50,3 +84,36 @@ func CustomHTTPErrorHandler(err error, c echo.Context) {
 		}
 	}
 }
+
+// fieldMessages turns validator errors into one readable message per field
+func fieldMessages(validationErrors validator.ValidationErrors) map[string]string {
+	fields := make(map[string]string, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields[fe.Field()] = fieldMessage(fe)
+	}
+	return fields
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	}
+	return fmt.Sprintf("failed the %s check", fe.Tag())
+}
@@ -2,51 +2,51 @@ package middlewares
 
 import (
 	"errors"
-	"fmt"
+	"job-portal/token"
 	"net/http"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
-// JWTSecret is the secret key used for signing the JWT. Replace it with your own secret.
-var JWTSecret = []byte("secret_key")
+// SessionChecker reports whether the session an access token belongs to is still active
+type SessionChecker interface {
+	IsActive(sessionID string) (bool, error)
+}
+
+// JWTConfig configures JWTMiddleware
+type JWTConfig struct {
+	Tokens   *token.Manager // Verifies access tokens
+	Sessions SessionChecker // Consulted on every request so revoked sessions are rejected; nil skips the check
+	CSRF     CSRFConfig     // Protects requests authenticated by the auth cookie
+}
 
-// Claims represents the custom claims in the JWT
-type Claims struct {
-	UserID string `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
-	jwt.RegisteredClaims
+// VerifiedEmailConfig configures RequireVerifiedEmail
+type VerifiedEmailConfig struct {
+	Enforce bool // Off lets unverified accounts through, e.g. for local development
 }
 
 // JWTMiddleware authenticates a JWT token and checks if the user's role matches any of the allowed roles.
-func JWTMiddleware(allowedRoles ...string) echo.MiddlewareFunc {
+// Without roles any authenticated user is let through; pair it with RequirePermission.
+func JWTMiddleware(config JWTConfig, allowedRoles ...string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			// Get the token from the Authorization header
-			authHeader := c.Request().Header.Get("Authorization")
-			if authHeader == "" {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Authorization header is required")
+			// Get the token from the Authorization header or, for browsers, the auth cookie
+			tokenString, fromCookie := extractToken(c)
+			if tokenString == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Authorization header or auth cookie is required")
 			}
 
-			// Validate the Authorization header format
-			// if !strings.HasPrefix(authHeader, "Bearer ") {
-			// 	return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token format, must start with 'Bearer '")
-			// }
-
-			tokenString := authHeader
-
-			// Parse and validate the token
-			claims := &Claims{}
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-				// Ensure the signing method is HMAC
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			// Browsers attach cookies to cross-site requests, so those need CSRF protection
+			if fromCookie {
+				if err := VerifyCSRF(c, config.CSRF); err != nil {
+					return err
 				}
-				return JWTSecret, nil
-			})
+			}
 
+			// Parse and validate the token against the active keys
+			claims, err := config.Tokens.Parse(tokenString)
 			if err != nil {
 				// Handle token parsing errors
 				if errors.Is(err, jwt.ErrTokenExpired) {
@@ -55,13 +55,19 @@ func JWTMiddleware(allowedRoles ...string) echo.MiddlewareFunc {
 				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token")
 			}
 
-			// Ensure the token is valid
-			if !token.Valid {
-				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token")
+			// Reject tokens whose session has been revoked
+			if config.Sessions != nil {
+				active, err := config.Sessions.IsActive(claims.SessionID)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, "Failed to verify session").SetInternal(err)
+				}
+				if !active {
+					return echo.NewHTTPError(http.StatusUnauthorized, "Session has been revoked")
+				}
 			}
 
 			// Check if the user's role matches any of the allowed roles
-			roleAllowed := false
+			roleAllowed := len(allowedRoles) == 0
 			for _, role := range allowedRoles {
 				if claims.Role == role {
 					roleAllowed = true
@@ -77,9 +83,39 @@ func JWTMiddleware(allowedRoles ...string) echo.MiddlewareFunc {
 			c.Set("userID", claims.UserID)
 			c.Set("email", claims.Email)
 			c.Set("role", claims.Role)
+			c.Set("sessionID", claims.SessionID)
+			c.Set("emailVerified", claims.EmailVerified)
 
 			// Proceed to the next handler
 			return next(c)
 		}
 	}
 }
+
+// extractToken reads the access token from "Authorization: Bearer <token>", a bare
+// Authorization header (kept for older clients) or the auth_token cookie set at login
+func extractToken(c echo.Context) (string, bool) {
+	if authHeader := strings.TrimSpace(c.Request().Header.Get(echo.HeaderAuthorization)); authHeader != "" {
+		if scheme, rest, found := strings.Cut(authHeader, " "); found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(rest), false
+		}
+		return authHeader, false
+	}
+	if cookie, err := c.Cookie("auth_token"); err == nil && cookie.Value != "" {
+		return cookie.Value, true
+	}
+	return "", false
+}
+
+// RequireVerifiedEmail refuses accounts that have not verified their email address.
+// It must run after JWTMiddleware, e.g. on routes that create or change data.
+func RequireVerifiedEmail(config VerifiedEmailConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if verified, _ := c.Get("emailVerified").(bool); config.Enforce && !verified {
+				return echo.NewHTTPError(http.StatusForbidden, "Email address is not verified; verify it and refresh your token")
+			}
+			return next(c)
+		}
+	}
+}
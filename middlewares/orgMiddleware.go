@@ -0,0 +1,68 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// OrgHeaderName is the request header that selects the active organization
+const OrgHeaderName = "X-Org-ID"
+
+// MembershipChecker looks up the role of a user in an organization; "" means the user is not a member
+type MembershipChecker interface {
+	MemberRole(orgID, userID string) (string, error)
+}
+
+// OrgConfig configures ActiveOrg
+type OrgConfig struct {
+	Memberships MembershipChecker // Checks that users belong to the organization they act for
+}
+
+// ActiveOrg resolves the organization the user acts for, from the :orgId path
+// parameter or else the X-Org-ID header, and checks that the user is a member.
+// It sets orgID and orgRole in the context. Without roles the organization is
+// optional; with roles it is required and the member must have one of them.
+// It must run after JWTMiddleware.
+func ActiveOrg(config OrgConfig, allowedRoles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			orgID := c.Param("orgId")
+			if orgID == "" {
+				orgID = c.Request().Header.Get(OrgHeaderName)
+			}
+			if orgID == "" {
+				if len(allowedRoles) > 0 {
+					return echo.NewHTTPError(http.StatusBadRequest, "An organization is required, set the "+OrgHeaderName+" header")
+				}
+				return next(c)
+			}
+
+			userID, _ := c.Get("userID").(string)
+			orgRole, err := config.Memberships.MemberRole(orgID, userID)
+			if err != nil {
+				return err
+			}
+			if orgRole == "" {
+				return echo.NewHTTPError(http.StatusForbidden, "You are not a member of this organization")
+			}
+
+			if len(allowedRoles) > 0 {
+				roleAllowed := false
+				for _, role := range allowedRoles {
+					if orgRole == role {
+						roleAllowed = true
+						break
+					}
+				}
+				if !roleAllowed {
+					return echo.NewHTTPError(http.StatusForbidden, "Access denied for this organization role")
+				}
+			}
+
+			c.Set("orgID", orgID)
+			c.Set("orgRole", orgRole)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"job-portal/policy"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequirePermission refuses users whose role, or role in the active organization,
+// does not grant every one of the permissions. It must run after JWTMiddleware,
+// and after ActiveOrg on routes that act for an organization. Checks that depend
+// on the resource, such as owning the job, are left to the services.
+func RequirePermission(permissions ...policy.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			role, _ := c.Get("role").(string)
+			orgRole, _ := c.Get("orgRole").(string)
+			for _, permission := range permissions {
+				if !policy.HasInOrg(role, orgRole, permission) {
+					return echo.NewHTTPError(http.StatusForbidden, "Your role lacks the "+string(permission)+" permission")
+				}
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,497 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"job-portal/geo"
+	"job-portal/models"
+	"job-portal/utils"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// All lists the migrations of the schema in order. Once released, a migration
+// must not change: add a new one instead. Backfills compute values the way the
+// server does, so they are given the server's geocoder, configured by GAZETTEER_FILE.
+func All(geocoder geo.Geocoder) []Migration {
+	return []Migration{
+		{
+			Version: 1,
+			Name:    "unique index on users.email",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				return createIndexes(ctx, db.Collection("users"), mongo.IndexModel{
+					Keys:    bson.D{{Key: "email", Value: 1}},
+					Options: options.Index().SetName("users_email_unique").SetUnique(true),
+				})
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				return dropIndexes(ctx, db.Collection("users"), "users_email_unique")
+			},
+		},
+		{
+			Version: 2,
+			Name:    "job listing and search indexes",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				return createIndexes(ctx, db.Collection("jobs"),
+					mongo.IndexModel{Keys: bson.D{{Key: "posted_at", Value: -1}}, Options: options.Index().SetName("jobs_posted_at")},
+					mongo.IndexModel{Keys: bson.D{{Key: "type", Value: 1}}, Options: options.Index().SetName("jobs_type")},
+					mongo.IndexModel{Keys: bson.D{{Key: "work_location", Value: 1}}, Options: options.Index().SetName("jobs_work_location")},
+					mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "apply_by", Value: 1}}, Options: options.Index().SetName("jobs_status_apply_by")},
+					mongo.IndexModel{Keys: bson.D{{Key: "created_by", Value: 1}}, Options: options.Index().SetName("jobs_created_by")},
+					mongo.IndexModel{
+						Keys: bson.D{
+							{Key: "title", Value: "text"},
+							{Key: "skills", Value: "text"},
+							{Key: "company_name", Value: "text"},
+							{Key: "description", Value: "text"},
+						},
+						Options: options.Index().SetName("job_text").SetWeights(bson.D{
+							{Key: "title", Value: 10},
+							{Key: "skills", Value: 5},
+							{Key: "company_name", Value: 3},
+							{Key: "description", Value: 1},
+						}),
+					},
+				)
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				return dropIndexes(ctx, db.Collection("jobs"),
+					"jobs_posted_at", "jobs_type", "jobs_work_location", "jobs_status_apply_by", "jobs_created_by", "job_text")
+			},
+		},
+		{
+			Version: 3,
+			Name:    "session, token and application indexes",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				err := createIndexes(ctx, db.Collection("sessions"),
+					mongo.IndexModel{Keys: bson.D{{Key: "refresh_token_hash", Value: 1}}, Options: options.Index().SetName("sessions_refresh_token_hash").SetUnique(true)},
+					mongo.IndexModel{Keys: bson.D{{Key: "previous_hashes", Value: 1}}, Options: options.Index().SetName("sessions_previous_hashes")},
+					mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}}, Options: options.Index().SetName("sessions_user_id")},
+				)
+				if err != nil {
+					return err
+				}
+				err = createIndexes(ctx, db.Collection("tokens"),
+					mongo.IndexModel{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetName("tokens_token_hash").SetUnique(true)},
+					mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}}, Options: options.Index().SetName("tokens_user_id_purpose")},
+				)
+				if err != nil {
+					return err
+				}
+				return createIndexes(ctx, db.Collection("applications"),
+					mongo.IndexModel{Keys: bson.D{{Key: "job_id", Value: 1}, {Key: "user_id", Value: 1}}, Options: options.Index().SetName("applications_job_id_user_id").SetUnique(true)},
+					mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}, Options: options.Index().SetName("applications_user_id_created_at")},
+				)
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				if err := dropIndexes(ctx, db.Collection("sessions"), "sessions_refresh_token_hash", "sessions_previous_hashes", "sessions_user_id"); err != nil {
+					return err
+				}
+				if err := dropIndexes(ctx, db.Collection("tokens"), "tokens_token_hash", "tokens_user_id_purpose"); err != nil {
+					return err
+				}
+				return dropIndexes(ctx, db.Collection("applications"), "applications_job_id_user_id", "applications_user_id_created_at")
+			},
+		},
+		{
+			// Accounts created before email verification existed were never sent a
+			// link, so they are trusted as verified rather than locked out
+			Version: 4,
+			Name:    "backfill users.email_verified",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("users").UpdateMany(ctx,
+					bson.M{"email_verified": bson.M{"$exists": false}},
+					bson.A{bson.M{"$set": bson.M{"email_verified": true, "verified_at": "$created_at"}}},
+				)
+				return err
+			},
+			// Irreversible: afterwards these users cannot be told apart from ones who verified
+		},
+		{
+			// Jobs created before statuses and versions were live and never edited under versioning
+			Version: 5,
+			Name:    "backfill jobs.status and jobs.version",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				jobs := db.Collection("jobs")
+				if _, err := jobs.UpdateMany(ctx, bson.M{"status": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"status": "published"}}); err != nil {
+					return err
+				}
+				_, err := jobs.UpdateMany(ctx, bson.M{"version": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"version": 1}})
+				return err
+			},
+			// Irreversible: the code treats a missing status and version the same way anyway
+		},
+		{
+			// ApplyLink was stored under the driver's default key until it got a bson tag
+			Version: 6,
+			Name:    "rename jobs.applylink to jobs.apply_link",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("jobs").UpdateMany(ctx,
+					bson.M{"applylink": bson.M{"$exists": true}},
+					bson.M{"$rename": bson.M{"applylink": "apply_link"}},
+				)
+				return err
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection("jobs").UpdateMany(ctx,
+					bson.M{"apply_link": bson.M{"$exists": true}},
+					bson.M{"$rename": bson.M{"apply_link": "applylink"}},
+				)
+				return err
+			},
+		},
+		{
+			// The values themselves are computed by the server at startup, from its exchange rates
+			Version: 7,
+			Name:    "indexes on normalized annual salaries",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				return createIndexes(ctx, db.Collection("jobs"),
+					mongo.IndexModel{Keys: bson.D{{Key: "annual_max_salary", Value: 1}, {Key: "_id", Value: 1}}, Options: options.Index().SetName("jobs_annual_max_salary")},
+					mongo.IndexModel{Keys: bson.D{{Key: "annual_min_salary", Value: 1}}, Options: options.Index().SetName("jobs_annual_min_salary")},
+				)
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				return dropIndexes(ctx, db.Collection("jobs"), "jobs_annual_max_salary", "jobs_annual_min_salary")
+			},
+		},
+		{
+			// New and edited jobs are geocoded by the server; this places the existing ones
+			Version: 8,
+			Name:    "geocode job locations and add a 2dsphere index",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				jobs := db.Collection("jobs")
+				if err := geocodeJobs(ctx, jobs, geocoder); err != nil {
+					return err
+				}
+				return createIndexes(ctx, jobs, mongo.IndexModel{
+					Keys:    bson.D{{Key: "geo", Value: "2dsphere"}},
+					Options: options.Index().SetName("jobs_geo"),
+				})
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				jobs := db.Collection("jobs")
+				if err := dropIndexes(ctx, jobs, "jobs_geo"); err != nil {
+					return err
+				}
+				_, err := jobs.UpdateMany(ctx, bson.M{}, bson.M{"$unset": bson.M{"geo": ""}})
+				return err
+			},
+		},
+		{
+			// Jobs used to carry their own copy of the company details
+			Version: 9,
+			Name:    "move embedded company details into a companies collection",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				err := createIndexes(ctx, db.Collection("companies"),
+					mongo.IndexModel{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetName("companies_slug_unique").SetUnique(true)},
+					mongo.IndexModel{Keys: bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}, Options: options.Index().SetName("companies_name")},
+				)
+				if err != nil {
+					return err
+				}
+				if err := createIndexes(ctx, db.Collection("jobs"), mongo.IndexModel{
+					Keys:    bson.D{{Key: "company_id", Value: 1}},
+					Options: options.Index().SetName("jobs_company_id"),
+				}); err != nil {
+					return err
+				}
+				return extractCompanies(ctx, db)
+			},
+			// Irreversible: the details now live only on the companies
+		},
+		{
+			Version: 10,
+			Name:    "organization, membership and invitation indexes",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				err := createIndexes(ctx, db.Collection("memberships"),
+					mongo.IndexModel{Keys: bson.D{{Key: "org_id", Value: 1}, {Key: "user_id", Value: 1}}, Options: options.Index().SetName("memberships_org_id_user_id").SetUnique(true)},
+					mongo.IndexModel{Keys: bson.D{{Key: "user_id", Value: 1}}, Options: options.Index().SetName("memberships_user_id")},
+				)
+				if err != nil {
+					return err
+				}
+				err = createIndexes(ctx, db.Collection("invitations"),
+					mongo.IndexModel{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetName("invitations_token_hash").SetUnique(true)},
+					mongo.IndexModel{Keys: bson.D{{Key: "org_id", Value: 1}, {Key: "email", Value: 1}}, Options: options.Index().SetName("invitations_org_id_email")},
+				)
+				if err != nil {
+					return err
+				}
+				return createIndexes(ctx, db.Collection("jobs"), mongo.IndexModel{
+					Keys:    bson.D{{Key: "org_id", Value: 1}},
+					Options: options.Index().SetName("jobs_org_id").SetSparse(true),
+				})
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				if err := dropIndexes(ctx, db.Collection("memberships"), "memberships_org_id_user_id", "memberships_user_id"); err != nil {
+					return err
+				}
+				if err := dropIndexes(ctx, db.Collection("invitations"), "invitations_token_hash", "invitations_org_id_email"); err != nil {
+					return err
+				}
+				return dropIndexes(ctx, db.Collection("jobs"), "jobs_org_id")
+			},
+		},
+		{
+			Version: 11,
+			Name:    "unique index on profiles.user_id",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				return createIndexes(ctx, db.Collection("profiles"), mongo.IndexModel{
+					Keys:    bson.D{{Key: "user_id", Value: 1}},
+					Options: options.Index().SetName("profiles_user_id_unique").SetUnique(true),
+				})
+			},
+			Down: func(ctx context.Context, db *mongo.Database) error {
+				return dropIndexes(ctx, db.Collection("profiles"), "profiles_user_id_unique")
+			},
+		},
+		{
+			// Users lost the job posting permissions; those who already post jobs
+			// or own companies become recruiters
+			Version: 12,
+			Name:    "promote posting users to recruiters",
+			Up:      promotePosters,
+			// Irreversible: promoted users cannot be told apart from appointed recruiters
+		},
+		{
+			// Emails are now stored and looked up in lower case
+			Version: 13,
+			Name:    "lower-case user emails",
+			Up:      lowerCaseEmails,
+			// Irreversible: the original spelling is not kept
+		},
+	}
+}
+
+// lowerCaseEmails stores every user's email trimmed and in lower case. Accounts
+// whose emails only differ in case cannot share one; they are listed in the
+// error, and left as they are, for an admin to merge or rename.
+func lowerCaseEmails(ctx context.Context, db *mongo.Database) error {
+	users := db.Collection("users")
+	cursor, err := users.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"email": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var conflicts []string
+	for cursor.Next(ctx) {
+		var user struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Email string             `bson:"email"`
+		}
+		if err := cursor.Decode(&user); err != nil {
+			return err
+		}
+		email := strings.ToLower(strings.TrimSpace(user.Email))
+		if email == user.Email {
+			continue
+		}
+		_, err := users.UpdateByID(ctx, user.ID, bson.M{"$set": bson.M{"email": email, "updated_at": time.Now()}})
+		if mongo.IsDuplicateKeyError(err) {
+			conflicts = append(conflicts, user.Email)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("these emails belong to accounts that differ only in case, resolve them and run the migration again: %s", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// promotePosters gives the recruiter role to every user who created a job or
+// company, so their own jobs and companies stay theirs to manage. Organization
+// members need no promotion: membership itself grants the permissions on the
+// organization's jobs and companies.
+func promotePosters(ctx context.Context, db *mongo.Database) error {
+	var posters []interface{}
+	for _, source := range []struct{ collection, field string }{
+		{"jobs", "created_by"},
+		{"companies", "created_by"},
+	} {
+		ids, err := db.Collection(source.collection).Distinct(ctx, source.field, bson.M{})
+		if err != nil {
+			return err
+		}
+		posters = append(posters, ids...)
+	}
+	if len(posters) == 0 {
+		return nil
+	}
+	_, err := db.Collection("users").UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": posters}, "role": models.RoleUser},
+		bson.M{"$set": bson.M{"role": models.RoleRecruiter, "updated_at": time.Now()}},
+	)
+	return err
+}
+
+// extractCompanies creates one company for every distinct company name on jobs
+// that do not reference a company yet, ignoring case and spacing, and points
+// the jobs at it. A company takes the name and owner of its oldest job, the
+// first logo found and the longest description.
+func extractCompanies(ctx context.Context, db *mongo.Database) error {
+	jobs, companies := db.Collection("jobs"), db.Collection("companies")
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetProjection(bson.M{"company_name": 1, "company_logo": 1, "company_description": 1, "created_by": 1})
+	cursor, err := jobs.Find(ctx, bson.M{"company_id": nil, "company_name": bson.M{"$nin": bson.A{"", nil}}}, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	type group struct {
+		company models.Company
+		jobIDs  bson.A
+	}
+	var order []string
+	groups := map[string]*group{}
+	for cursor.Next(ctx) {
+		var job struct {
+			ID          primitive.ObjectID `bson:"_id"`
+			Name        string             `bson:"company_name"`
+			Logo        string             `bson:"company_logo"`
+			Description string             `bson:"company_description"`
+			CreatedBy   primitive.ObjectID `bson:"created_by"`
+		}
+		if err := cursor.Decode(&job); err != nil {
+			return err
+		}
+		key := strings.Join(strings.Fields(strings.ToLower(job.Name)), " ")
+		if key == "" {
+			continue
+		}
+		g, ok := groups[key]
+		if !ok {
+			g = &group{company: models.Company{Name: strings.TrimSpace(job.Name), CreatedBy: job.CreatedBy}}
+			groups[key] = g
+			order = append(order, key)
+		}
+		if g.company.Logo == "" {
+			g.company.Logo = job.Logo
+		}
+		if len(job.Description) > len(g.company.Description) {
+			g.company.Description = job.Description
+		}
+		g.jobIDs = append(g.jobIDs, job.ID)
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	for _, key := range order {
+		g := groups[key]
+		company, err := findOrCreateCompany(ctx, companies, key, &g.company)
+		if err != nil {
+			return err
+		}
+		_, err = jobs.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": g.jobIDs}}, bson.M{
+			"$set":   bson.M{"company_id": company.ID, "company_name": company.Name, "company_logo": company.Logo},
+			"$unset": bson.M{"company_description": ""},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findOrCreateCompany reuses a company with the same name, which an interrupted
+// earlier run may have created, or inserts the draft under the first free slug
+func findOrCreateCompany(ctx context.Context, companies *mongo.Collection, key string, draft *models.Company) (*models.Company, error) {
+	draft.ID = primitive.NewObjectID()
+	base := utils.Slugify(draft.Name)
+	if base == "" {
+		base = "company-" + draft.ID.Hex()
+	}
+	for attempt := 1; ; attempt++ {
+		slug := base
+		if attempt > 1 {
+			slug = fmt.Sprintf("%s-%d", base, attempt)
+		}
+
+		var existing models.Company
+		err := companies.FindOne(ctx, bson.M{"slug": slug}).Decode(&existing)
+		if err == nil {
+			if strings.Join(strings.Fields(strings.ToLower(existing.Name)), " ") == key {
+				return &existing, nil
+			}
+			continue // Taken by another company
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+
+		now := time.Now()
+		draft.Slug = slug
+		draft.CreatedAt, draft.UpdatedAt = now, now
+		if _, err := companies.InsertOne(ctx, draft); err != nil {
+			return nil, err
+		}
+		return draft, nil
+	}
+}
+
+// geocodeJobs sets the geo point of every job without one whose location the geocoder knows
+func geocodeJobs(ctx context.Context, jobs *mongo.Collection, geocoder geo.Geocoder) error {
+	cursor, err := jobs.Find(ctx, bson.M{"geo": nil}, options.Find().SetProjection(bson.M{"location": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var job struct {
+			ID       interface{} `bson:"_id"`
+			Location string      `bson:"location"`
+		}
+		if err := cursor.Decode(&job); err != nil {
+			return err
+		}
+		point, err := geocoder.Geocode(ctx, job.Location)
+		if errors.Is(err, geo.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := jobs.UpdateByID(ctx, job.ID, bson.M{"$set": bson.M{"geo": models.NewGeoPoint(point.Lat, point.Lng)}}); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// createIndexes creates the indexes; creating one that already exists with the same options is a no-op
+func createIndexes(ctx context.Context, collection *mongo.Collection, indexes ...mongo.IndexModel) error {
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// dropIndexes drops the named indexes, ignoring ones that do not exist
+func dropIndexes(ctx context.Context, collection *mongo.Collection, names ...string) error {
+	for _, name := range names {
+		_, err := collection.Indexes().DropOne(ctx, name)
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && (cmdErr.Code == 26 || cmdErr.Code == 27) {
+			continue // NamespaceNotFound or IndexNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
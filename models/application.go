@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Application represents a candidate's application to a job posting
+type Application struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	JobID       primitive.ObjectID `json:"job_id" bson:"job_id"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	CoverLetter string             `json:"cover_letter" bson:"cover_letter"`
+	ResumeLink  string             `json:"resume_link" bson:"resume_link" validate:"omitempty,url"` // Optional link to the candidate's resume
+	Status      string             `json:"status" bson:"status"`
+	History     []StatusChange     `json:"history" bson:"history"` // Every status the application has gone through
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// StatusChange records a single step of an application's status pipeline
+type StatusChange struct {
+	Status    string    `json:"status" bson:"status"`
+	ChangedBy string    `json:"changed_by" bson:"changed_by"` // ID of the user who made the change
+	ChangedAt time.Time `json:"changed_at" bson:"changed_at"`
+}
+
+// Enums for Application Status
+const (
+	StatusSubmitted = "submitted"
+	StatusScreening = "screening"
+	StatusInterview = "interview"
+	StatusOffer     = "offer"
+	StatusRejected  = "rejected"
+	StatusWithdrawn = "withdrawn"
+)
+
+// applicationTransitions lists the statuses an application may move to from each status
+var applicationTransitions = map[string][]string{
+	StatusSubmitted: {StatusScreening, StatusRejected, StatusWithdrawn},
+	StatusScreening: {StatusInterview, StatusRejected, StatusWithdrawn},
+	StatusInterview: {StatusOffer, StatusRejected, StatusWithdrawn},
+	StatusOffer:     {StatusWithdrawn},
+}
+
+// CanTransitionApplication reports whether an application in status from may move to status to
+func CanTransitionApplication(from, to string) bool {
+	for _, next := range applicationTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Company is an employer that jobs are posted for. Jobs reference it by ID and
+// keep a copy of its name and logo, which the server refreshes when they change.
+type Company struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Name        string             `json:"name" bson:"name" validate:"required,max=100"`
+	Slug        string             `json:"slug" bson:"slug"` // Unique URL name derived from the name, set by the server
+	Logo        string             `json:"logo" bson:"logo" validate:"omitempty,url"`
+	Description string             `json:"description" bson:"description" validate:"max=5000"`
+	Website     string             `json:"website" bson:"website" validate:"omitempty,url"`
+	Verified    bool               `json:"verified" bson:"verified"`                 // Confirmed by an admin to be the real company
+	CreatedBy   primitive.ObjectID `json:"created_by" bson:"created_by"`             // Owner, who may edit it and post jobs for it
+	OrgID       primitive.ObjectID `json:"org_id,omitempty" bson:"org_id,omitempty"` // Organization whose members may also edit it and post jobs for it
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
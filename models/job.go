@@ -12,29 +12,52 @@ type Job struct {
 	Title            string             `json:"title" validate:"required"`
 	Description      string             `json:"description" validate:"required"`
 	Location         string             `json:"location" validate:"required"`
+	Geo              *GeoPoint          `json:"geo,omitempty" bson:"geo,omitempty"`         // Where Location is, set by the server when it can be geocoded
+	DistanceKm       *float64           `json:"distance_km,omitempty" bson:"-"`             // Distance from a near search's point, only set in its results
 	MinSalary        float64            `json:"min_salary" bson:"min_salary" validate:"required"` // Minimum salary (numeric)
 	MaxSalary        float64            `json:"max_salary" bson:"max_salary" validate:"required"` // Maximum salary (numeric)
+	Currency         string             `json:"currency" bson:"currency" validate:"omitempty,iso4217"`          // ISO 4217 code of the salary, the base currency when empty
+	Period           string             `json:"period" bson:"period" validate:"omitempty,oneof=hour month year"` // What the salary is paid per, a year when empty
+	AnnualMinSalary  float64            `json:"annual_min_salary" bson:"annual_min_salary"`                    // MinSalary per year in the base currency, set by the server
+	AnnualMaxSalary  float64            `json:"annual_max_salary" bson:"annual_max_salary"`                    // MaxSalary per year in the base currency, set by the server
 	Type             string             `json:"type" validate:"required,oneof=full-time part-time contract"`
 	Experience       string             `json:"experience" validate:"required,oneof=entry mid senior"`
 	Education        string             `json:"education" validate:"required,oneof=bachelor master phd"`
 	Skills           []string           `json:"skills" validate:"required"`           // List of required skills
 	Responsibilities []string           `json:"responsibilities" validate:"required"` // List of job responsibilities
 	Benefits         []string           `json:"benefits" validate:"required"`         // List of benefits provided
-	ApplyLink        string             `json:"apply_link" validate:"required,url"`   // URL where users can apply
+	ApplyLink        string             `json:"apply_link" bson:"apply_link" validate:"required,url"` // URL where users can apply
 	PostedAt         time.Time          `json:"posted_at" bson:"posted_at"`
 	ApplyBy          time.Time          `json:"apply_by" bson:"apply_by"`
 	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt        time.Time          `json:"updated_at" bson:"updated_at"`
+	CreatedBy        primitive.ObjectID `json:"created_by,omitempty" bson:"created_by,omitempty"` // ID of the user who posted the job
+	OrgID            primitive.ObjectID `json:"org_id,omitempty" bson:"org_id,omitempty"`         // Organization whose members may all manage the job
+	Version          int64              `json:"version" bson:"version"`                           // Bumped on every update, exposed as the ETag
+	Status           string             `json:"status" bson:"status"`                             // Lifecycle status, changed only through transitions
+	Score            float64            `json:"score,omitempty" bson:"score,omitempty"`           // Search relevance, only set in search results
 
-	// Company Info (nested object)
+	// Company Info: the company the job is posted for, with copies of its name and
+	// logo for listings and search that the server keeps in sync with the company
+	CompanyID        primitive.ObjectID `json:"company_id" bson:"company_id,omitempty"`
 	CompanyName      string `json:"company_name" bson:"company_name"`
 	CompanyLogo      string `json:"company_logo" bson:"company_logo"`
-	CompanyDescription string `json:"company_description" bson:"company_description"`
 
 	// Work Location (On-site, Remote, Hybrid)
 	WorkLocation     string `json:"work_location" bson:"work_location" validate:"required,oneof=on-site remote hybrid"`
 }
 
+// GeoPoint is a GeoJSON point, which Mongo's 2dsphere indexes understand
+type GeoPoint struct {
+	Type        string    `json:"type" bson:"type"`               // Always "Point"
+	Coordinates []float64 `json:"coordinates" bson:"coordinates"` // Longitude, then latitude
+}
+
+// NewGeoPoint creates a GeoJSON point from a latitude and a longitude
+func NewGeoPoint(lat, lng float64) *GeoPoint {
+	return &GeoPoint{Type: "Point", Coordinates: []float64{lng, lat}}
+}
+
 // Enums for Job Fields
 const (
 	FullTime   = "full-time"
@@ -50,3 +73,72 @@ const (
 	Remote     = "remote"
 	Hybrid     = "hybrid"
 )
+
+// Salary pay periods
+const (
+	PeriodHour  = "hour"
+	PeriodMonth = "month"
+	PeriodYear  = "year"
+)
+
+// PeriodsPerYear is how many of each pay period make a year, assuming 40-hour weeks
+var PeriodsPerYear = map[string]float64{
+	PeriodHour:  52 * 40,
+	PeriodMonth: 12,
+	PeriodYear:  1,
+}
+
+// Valid values of each enum field, in display order
+var (
+	JobTypes         = []string{FullTime, PartTime, Contract}
+	ExperienceLevels = []string{EntryLevel, MidLevel, Senior}
+	EducationLevels  = []string{Bachelor, Master, PhD}
+	WorkLocations    = []string{OnSite, Remote, Hybrid}
+	SalaryPeriods    = []string{PeriodHour, PeriodMonth, PeriodYear}
+)
+
+// Job lifecycle statuses
+const (
+	JobStatusDraft     = "draft"
+	JobStatusPublished = "published"
+	JobStatusPaused    = "paused"
+	JobStatusClosed    = "closed"
+	JobStatusExpired   = "expired"
+)
+
+// jobTransitions lists the statuses a job may move to from each status
+var jobTransitions = map[string][]string{
+	JobStatusDraft:     {JobStatusPublished, JobStatusClosed},
+	JobStatusPublished: {JobStatusPaused, JobStatusClosed, JobStatusExpired},
+	JobStatusPaused:    {JobStatusPublished, JobStatusClosed, JobStatusExpired},
+	JobStatusExpired:   {JobStatusClosed},
+}
+
+// CanTransitionJob reports whether a job in status from may move to status to
+func CanTransitionJob(from, to string) bool {
+	for _, next := range jobTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentStatus returns the job's status. Jobs stored before statuses existed were live, so they count as published.
+func (j *Job) CurrentStatus() string {
+	if j.Status == "" {
+		return JobStatusPublished
+	}
+	return j.Status
+}
+
+// IsVisible reports whether the public can see the job at the given time: it is
+// published and its posting time, which may be scheduled in the future, has come
+func (j *Job) IsVisible(at time.Time) bool {
+	return j.CurrentStatus() == JobStatusPublished && !j.PostedAt.After(at)
+}
+
+// IsOpen reports whether the job is visible and still taking applications at the given time
+func (j *Job) IsOpen(at time.Time) bool {
+	return j.IsVisible(at) && (j.ApplyBy.IsZero() || j.ApplyBy.After(at))
+}
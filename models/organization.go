@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Organization is a recruiting team. Its members share the companies and jobs
+// created on behalf of the organization.
+type Organization struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Name      string             `json:"name" bson:"name" validate:"required,max=100"`
+	CreatedBy primitive.ObjectID `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// Membership gives a user a role in an organization. A user has at most one
+// membership per organization.
+type Membership struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	OrgID     primitive.ObjectID `json:"org_id" bson:"org_id"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Role      string             `json:"role" bson:"role"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// Invitation asks the owner of an email address to join an organization. The
+// emailed token is single-use and only its hash is stored.
+type Invitation struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	OrgID      primitive.ObjectID `json:"org_id" bson:"org_id"`
+	Email      string             `json:"email" bson:"email" validate:"required,email"`
+	Role       string             `json:"role" bson:"role" validate:"required,oneof=admin member"`
+	TokenHash  string             `json:"-" bson:"token_hash"`
+	InvitedBy  primitive.ObjectID `json:"invited_by" bson:"invited_by"`
+	ExpiresAt  time.Time          `json:"expires_at" bson:"expires_at"`
+	AcceptedAt *time.Time         `json:"accepted_at,omitempty" bson:"accepted_at,omitempty"`
+	AcceptedBy primitive.ObjectID `json:"accepted_by,omitempty" bson:"accepted_by,omitempty"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// Organization Roles. The owner manages everything, admins manage members and
+// invitations, and members manage the organization's companies and jobs.
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// OrgRoles lists the organization roles, most privileged first
+var OrgRoles = []string{OrgRoleOwner, OrgRoleAdmin, OrgRoleMember}
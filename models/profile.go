@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Profile is what a candidate shows recruiters. Each user has at most one.
+type Profile struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID      primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Headline    string             `json:"headline" bson:"headline" validate:"max=200"` // e.g. "Backend engineer, Go and Kubernetes"
+	Skills      []string           `json:"skills" bson:"skills" validate:"max=50,dive,required,max=50"`
+	Experience  []ExperienceEntry  `json:"experience" bson:"experience" validate:"max=30,dive"`
+	Education   []EducationEntry   `json:"education" bson:"education" validate:"max=10,dive"`
+	Location    string             `json:"location" bson:"location" validate:"max=100"`
+	Preferences ProfilePreferences `json:"preferences" bson:"preferences"`
+	Resume      *Resume            `json:"resume,omitempty" bson:"resume,omitempty"` // Set by uploading a resume
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// ExperienceEntry is a past or current position
+type ExperienceEntry struct {
+	Title       string     `json:"title" bson:"title" validate:"required,max=100"`
+	Company     string     `json:"company" bson:"company" validate:"required,max=100"`
+	Location    string     `json:"location" bson:"location" validate:"max=100"`
+	StartDate   time.Time  `json:"start_date" bson:"start_date" validate:"required"`
+	EndDate     *time.Time `json:"end_date,omitempty" bson:"end_date,omitempty"` // Unset for the current position
+	Description string     `json:"description" bson:"description" validate:"max=2000"`
+}
+
+// EducationEntry is a degree, at the same levels jobs ask for
+type EducationEntry struct {
+	Level          string `json:"level" bson:"level" validate:"required,oneof=bachelor master phd"`
+	Field          string `json:"field" bson:"field" validate:"max=100"`
+	Institution    string `json:"institution" bson:"institution" validate:"required,max=100"`
+	GraduationYear int    `json:"graduation_year,omitempty" bson:"graduation_year,omitempty" validate:"omitempty,min=1950,max=2100"`
+}
+
+// ProfilePreferences describes the jobs a candidate is looking for
+type ProfilePreferences struct {
+	JobTypes      []string `json:"job_types" bson:"job_types" validate:"dive,oneof=full-time part-time contract"`
+	WorkLocations []string `json:"work_locations" bson:"work_locations" validate:"dive,oneof=on-site remote hybrid"`
+}
+
+// Resume describes an uploaded resume; the file itself lives in blob storage
+type Resume struct {
+	Key         string    `json:"-" bson:"key"` // Blob storage key
+	FileName    string    `json:"file_name" bson:"file_name"`
+	ContentType string    `json:"content_type" bson:"content_type"`
+	Size        int64     `json:"size" bson:"size"`
+	UploadedAt  time.Time `json:"uploaded_at" bson:"uploaded_at"`
+}
@@ -15,11 +15,13 @@ const (
 
 // User struct with oneof validation for roles
 type User struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	Name      string             `json:"name" validate:"required"`
-	Email     string             `json:"email" validate:"required,email" bson:"email"`
-	Password  string             `json:"password" validate:"required"`
-	Role      string             `json:"role" validate:"required,oneof=admin user recruiter"` // Oneof validation for roles
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Name          string             `json:"name" validate:"required"`
+	Email         string             `json:"email" validate:"required,email" bson:"email"`
+	Password      string             `json:"-" validate:"required"`                               // bcrypt hash, never serialized
+	Role          string             `json:"role" validate:"required,oneof=admin user recruiter"` // Oneof validation for roles
+	EmailVerified bool               `json:"email_verified" bson:"email_verified"`
+	VerifiedAt    *time.Time         `json:"verified_at,omitempty" bson:"verified_at,omitempty"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`
 }
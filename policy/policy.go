@@ -0,0 +1,103 @@
+package policy
+
+import "job-portal/models"
+
+// Permission is something a role allows. Permissions ending in ":own" only apply
+// to resources the user created or shares through an organization; the matching
+// ":any" permission applies to every resource.
+//
+// Authority comes from two places. The platform role grants permissions on the
+// user's own resources and, for ":any", on everyone's. Belonging to an
+// organization grants memberPermissions on the organization's resources,
+// whatever the platform role: every teammate, owner, admin or member, manages
+// the organization's jobs and companies and reviews their applications. The
+// organization role only decides who manages the organization itself.
+type Permission string
+
+// Permissions
+const (
+	JobCreate         Permission = "job:create"
+	JobUpdateOwn      Permission = "job:update:own" // Edit, publish, pause, close and delete, and see drafts
+	JobUpdateAny      Permission = "job:update:any"
+	ApplicationCreate Permission = "application:create"
+	ApplicationReview Permission = "application:review" // Review the applications of jobs the user may update
+	CompanyCreate     Permission = "company:create"
+	CompanyUpdateOwn  Permission = "company:update:own" // Edit and delete, and post jobs for the company
+	CompanyUpdateAny  Permission = "company:update:any"
+	CompanyVerify     Permission = "company:verify"
+	OrgCreate         Permission = "org:create"
+	UserManage        Permission = "user:manage"
+)
+
+// Actions that have an own and an any variant
+const (
+	JobUpdate     = "job:update"
+	CompanyUpdate = "company:update"
+)
+
+// rolePermissions is the role/permission matrix. Users are candidates; posting
+// jobs and reviewing applications takes a recruiter, whom an admin appoints.
+var rolePermissions = map[string][]Permission{
+	models.RoleUser: {
+		ApplicationCreate,
+	},
+	models.RoleRecruiter: {
+		JobCreate, JobUpdateOwn, ApplicationReview,
+		CompanyCreate, CompanyUpdateOwn, OrgCreate,
+	},
+	models.RoleAdmin: {
+		JobCreate, JobUpdateOwn, JobUpdateAny, ApplicationReview,
+		CompanyCreate, CompanyUpdateOwn, CompanyUpdateAny, CompanyVerify,
+		OrgCreate, UserManage,
+	},
+}
+
+// memberPermissions is what belonging to an organization grants on its resources
+var memberPermissions = []Permission{
+	JobCreate, JobUpdateOwn, ApplicationReview,
+	CompanyCreate, CompanyUpdateOwn,
+}
+
+// Has reports whether the role grants the permission. Unknown roles grant nothing.
+func Has(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// HasInOrg reports whether the role, or membership of the organization a
+// resource belongs to, grants the permission. orgRole is the user's role in
+// that organization, "" if they are not a member.
+func HasInOrg(role, orgRole string, permission Permission) bool {
+	if Has(role, permission) {
+		return true
+	}
+	if orgRole == "" {
+		return false
+	}
+	for _, granted := range memberPermissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions lists the permissions the role grants
+func Permissions(role string) []Permission {
+	return append([]Permission(nil), rolePermissions[role]...)
+}
+
+// CanActOn is the resource-level check: it reports whether the user may perform
+// action on a resource, given their platform role, their role in the resource's
+// organization ("" if none) and whether they created the resource
+func CanActOn(role, orgRole, action string, created bool) bool {
+	if Has(role, Permission(action+":any")) {
+		return true
+	}
+	own := Permission(action + ":own")
+	return created && Has(role, own) || HasInOrg("", orgRole, own)
+}
@@ -0,0 +1,102 @@
+package policy
+
+import (
+	"job-portal/models"
+	"testing"
+)
+
+func TestHas(t *testing.T) {
+	all := []Permission{
+		JobCreate, JobUpdateOwn, JobUpdateAny, ApplicationCreate, ApplicationReview,
+		CompanyCreate, CompanyUpdateOwn, CompanyUpdateAny, CompanyVerify, OrgCreate, UserManage,
+	}
+	granted := map[string][]Permission{
+		models.RoleUser: {ApplicationCreate},
+		models.RoleRecruiter: {
+			JobCreate, JobUpdateOwn, ApplicationReview, CompanyCreate, CompanyUpdateOwn, OrgCreate,
+		},
+		models.RoleAdmin: {
+			JobCreate, JobUpdateOwn, JobUpdateAny, ApplicationReview,
+			CompanyCreate, CompanyUpdateOwn, CompanyUpdateAny, CompanyVerify, OrgCreate, UserManage,
+		},
+		"guest": nil,
+		"":      nil,
+	}
+
+	for role, permissions := range granted {
+		want := map[Permission]bool{}
+		for _, p := range permissions {
+			want[p] = true
+		}
+		for _, p := range all {
+			if got := Has(role, p); got != want[p] {
+				t.Errorf("Has(%q, %q) = %v, want %v", role, p, got, want[p])
+			}
+		}
+	}
+}
+
+func TestHasInOrg(t *testing.T) {
+	tests := []struct {
+		role, orgRole string
+		permission    Permission
+		want          bool
+	}{
+		{models.RoleUser, "", JobCreate, false},
+		{models.RoleUser, models.OrgRoleMember, JobCreate, true},
+		{models.RoleUser, models.OrgRoleMember, ApplicationReview, true},
+		{models.RoleUser, models.OrgRoleMember, CompanyCreate, true},
+		{models.RoleUser, models.OrgRoleOwner, JobUpdateAny, false}, // Membership only reaches the organization's resources
+		{models.RoleUser, models.OrgRoleOwner, OrgCreate, false},
+		{models.RoleUser, models.OrgRoleAdmin, UserManage, false},
+		{models.RoleRecruiter, "", JobCreate, true},
+		{models.RoleAdmin, "", CompanyVerify, true},
+	}
+
+	for _, tt := range tests {
+		if got := HasInOrg(tt.role, tt.orgRole, tt.permission); got != tt.want {
+			t.Errorf("HasInOrg(%q, %q, %q) = %v, want %v", tt.role, tt.orgRole, tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestCanActOn(t *testing.T) {
+	tests := []struct {
+		role    string
+		orgRole string
+		action  string
+		created bool
+		want    bool
+	}{
+		{models.RoleUser, "", JobUpdate, true, false},
+		{models.RoleUser, "", JobUpdate, false, false},
+		{models.RoleUser, "", CompanyUpdate, true, false},
+		{models.RoleUser, models.OrgRoleMember, JobUpdate, false, true}, // Invited teammates manage the organization's jobs
+		{models.RoleUser, models.OrgRoleMember, CompanyUpdate, false, true},
+		{models.RoleRecruiter, "", JobUpdate, true, true},
+		{models.RoleRecruiter, "", JobUpdate, false, false},
+		{models.RoleRecruiter, models.OrgRoleAdmin, JobUpdate, false, true},
+		{models.RoleRecruiter, "", CompanyUpdate, true, true},
+		{models.RoleRecruiter, "", CompanyUpdate, false, false},
+		{models.RoleAdmin, "", JobUpdate, true, true},
+		{models.RoleAdmin, "", JobUpdate, false, true},
+		{models.RoleAdmin, "", CompanyUpdate, false, true},
+		{"guest", "", JobUpdate, true, false},
+		{models.RoleAdmin, "", "job:delete", false, false}, // Unknown actions grant nothing
+		{models.RoleUser, models.OrgRoleOwner, "job:delete", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := CanActOn(tt.role, tt.orgRole, tt.action, tt.created); got != tt.want {
+			t.Errorf("CanActOn(%q, %q, %q, created=%v) = %v, want %v", tt.role, tt.orgRole, tt.action, tt.created, got, tt.want)
+		}
+	}
+}
+
+func TestPermissionsReturnsACopy(t *testing.T) {
+	permissions := Permissions(models.RoleRecruiter)
+	permissions[0] = UserManage
+	if Has(models.RoleRecruiter, UserManage) {
+		t.Fatal("changing the returned slice changed the matrix")
+	}
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+	"job-portal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ApplicationRepository stores job applications, at most one per job and candidate
+type ApplicationRepository interface {
+	// Create stores a new application, failing with ErrDuplicate if the user already applied to the job
+	Create(ctx context.Context, application *models.Application) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Application, error)
+	// ListByJob returns the applications to a job, newest first
+	ListByJob(ctx context.Context, jobID primitive.ObjectID) ([]models.Application, error)
+	// ListByUser returns the applications of a user, newest first
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Application, error)
+	// UpdateStatus records a status change if the application is still in the
+	// from status, failing with ErrVersionConflict otherwise
+	UpdateStatus(ctx context.Context, id primitive.ObjectID, from string, change models.StatusChange) error
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"job-portal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CompanyRepository stores companies
+type CompanyRepository interface {
+	// Create stores a new company, failing with ErrDuplicate if the slug is taken
+	Create(ctx context.Context, company *models.Company) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Company, error)
+	FindBySlug(ctx context.Context, slug string) (*models.Company, error)
+	// List returns one page of companies ordered by name, and the total number of companies
+	List(ctx context.Context, skip, limit int64) ([]models.Company, int64, error)
+	// Update sets the given fields and returns the updated company
+	Update(ctx context.Context, id primitive.ObjectID, fields map[string]interface{}) (*models.Company, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"job-portal/models"
+	"math"
+)
+
+// Facets that can be counted for a job listing
+const (
+	FacetType         = "type"
+	FacetWorkLocation = "work_location"
+	FacetExperience   = "experience"
+	FacetEducation    = "education"
+	FacetSalaryBucket = "salary_bucket"
+)
+
+// JobFacets lists every facet in the order they are reported
+var JobFacets = []string{FacetType, FacetWorkLocation, FacetExperience, FacetEducation, FacetSalaryBucket}
+
+// FacetCount is the number of jobs that would match if the value were selected
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// SalaryBucket is one salary range offered as a facet. A job falls into it the
+// same way it matches a salaryRange filter with these bounds, so a job whose
+// range spans several buckets is counted in each.
+type SalaryBucket struct {
+	Label string
+	Min   float64
+	Max   float64 // math.Inf(1) for the open-ended top bucket
+}
+
+// SalaryBuckets are the salary ranges counted by the salary_bucket facet
+var SalaryBuckets = []SalaryBucket{
+	{Label: "0-25000", Min: 0, Max: 25000},
+	{Label: "25000-50000", Min: 25000, Max: 50000},
+	{Label: "50000-75000", Min: 50000, Max: 75000},
+	{Label: "75000-100000", Min: 75000, Max: 100000},
+	{Label: "100000-150000", Min: 100000, Max: 150000},
+	{Label: "150000+", Min: 150000, Max: math.Inf(1)},
+}
+
+// facetValues lists the values of each enum facet, so values without jobs are reported with 0
+var facetValues = map[string][]string{
+	FacetType:         models.JobTypes,
+	FacetWorkLocation: models.WorkLocations,
+	FacetExperience:   models.ExperienceLevels,
+	FacetEducation:    models.EducationLevels,
+}
+
+// splitFacetFilter separates the conditions every facet shares (base) from the
+// selections a facet may drop to count its own values (selections)
+func splitFacetFilter(filter JobFilter) (base, selections JobFilter) {
+	base = filter
+	base.Types, base.WorkLocations, base.Experience, base.Education = nil, nil, nil, nil
+	base.MinSalary, base.MaxSalary = nil, nil
+	selections = JobFilter{
+		Types:         filter.Types,
+		WorkLocations: filter.WorkLocations,
+		Experience:    filter.Experience,
+		Education:     filter.Education,
+		MinSalary:     filter.MinSalary,
+		MaxSalary:     filter.MaxSalary,
+	}
+	return base, selections
+}
+
+// withoutFacet drops the facet's own selection, so its counts show what
+// selecting another value instead would return
+func withoutFacet(selections JobFilter, facet string) JobFilter {
+	switch facet {
+	case FacetType:
+		selections.Types = nil
+	case FacetWorkLocation:
+		selections.WorkLocations = nil
+	case FacetExperience:
+		selections.Experience = nil
+	case FacetEducation:
+		selections.Education = nil
+	case FacetSalaryBucket:
+		selections.MinSalary, selections.MaxSalary = nil, nil
+	}
+	return selections
+}
+
+// inBucket narrows the selections to one salary bucket
+func inBucket(selections JobFilter, bucket SalaryBucket) JobFilter {
+	min := bucket.Min
+	selections.MinSalary = &min
+	if !math.IsInf(bucket.Max, 1) {
+		max := bucket.Max
+		selections.MaxSalary = &max
+	}
+	return selections
+}
+
+// enumFacetCounts orders counts by the facet's known values, filling in zeros
+func enumFacetCounts(facet string, counts map[string]int64) []FacetCount {
+	result := []FacetCount{}
+	for _, value := range facetValues[facet] {
+		result = append(result, FacetCount{Value: value, Count: counts[value]})
+	}
+	return result
+}
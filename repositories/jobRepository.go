@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"job-portal/currency"
+	"job-portal/geo"
+	"job-portal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// JobFilter is a parsed job listing query. Zero values mean "no filter", and a
+// job matches a list of values when it has any of them.
+type JobFilter struct {
+	PostedAfter   time.Time  // Only jobs posted at or after this time
+	PostedBefore  time.Time  // Only jobs posted before this time
+	Types         []string   // full-time, part-time or contract
+	Experience    []string   // entry, mid or senior
+	Education     []string   // bachelor, master or phd
+	WorkLocations []string   // on-site, remote or hybrid
+	Skills        []string   // Required skills, matched case-insensitively
+	AllSkills     bool       // Jobs must require every one of Skills rather than any
+	Location      string     // Jobs whose location contains this, case-insensitively
+	Company       string     // Jobs whose company name contains this, case-insensitively
+	MinSalary     *float64   // Jobs whose annual salary range in the base currency reaches this
+	MaxSalary     *float64   // Jobs whose annual salary range in the base currency starts at or below this
+	Search        string     // Full-text query over title, description, skills and company; see TextQuery
+	Near          *geo.Point // Only jobs within RadiusKm of this point, and remote jobs unless ExcludeRemote
+	RadiusKm      float64    // Radius of the Near filter
+	ExcludeRemote bool       // Leave out remote jobs that are not within the radius of Near
+
+	OpenAt    time.Time          // Only jobs that are published, posted and taking applications at this time
+	CreatedBy primitive.ObjectID // Only jobs posted by this user
+	CompanyID primitive.ObjectID // Only jobs posted for this company
+	OrgID     primitive.ObjectID // Only jobs belonging to this organization
+	Status    string             // Only jobs in this lifecycle status
+}
+
+// Sort orders for job listings. Ties are broken by ID so every order is total,
+// which keyset pagination relies on.
+const (
+	SortPostedAtAsc  = "posted_at"
+	SortPostedAtDesc = "-posted_at"
+	SortSalaryAsc    = "salary" // By annual_max_salary, so salaries in any currency and period compare
+	SortSalaryDesc   = "-salary"
+	SortRelevance    = "relevance" // By text score, best first; needs a search
+)
+
+// JobSorts lists every sort order
+var JobSorts = []string{SortPostedAtAsc, SortPostedAtDesc, SortSalaryAsc, SortSalaryDesc, SortRelevance}
+
+// JobPosition marks a job's place in a sort order. A page that starts after a
+// position continues exactly where the previous page ended, even as jobs are added.
+type JobPosition struct {
+	ID       primitive.ObjectID `json:"id"`
+	PostedAt time.Time          `json:"posted_at,omitempty"` // For the posted_at orders
+	Value    float64            `json:"value,omitempty"`     // Salary or text score, for the other orders
+}
+
+// PositionOf returns the position of a listed job in the sort order
+func PositionOf(job *models.Job, sort string) JobPosition {
+	position := JobPosition{ID: job.ID}
+	switch sort {
+	case SortPostedAtAsc, SortPostedAtDesc:
+		position.PostedAt = job.PostedAt
+	case SortSalaryAsc, SortSalaryDesc:
+		position.Value = job.AnnualMaxSalary
+	case SortRelevance:
+		position.Value = job.Score
+	}
+	return position
+}
+
+// JobQuery selects one page of a listing
+type JobQuery struct {
+	Sort  string
+	After *JobPosition // Keyset pagination: start after this position; Skip is then ignored
+	Skip  int64        // Offset pagination
+	Limit int64
+}
+
+// jobTextWeights weighs matches in each field, like the job_text index created by the migrations
+var jobTextWeights = map[string]int{
+	"title":        10,
+	"skills":       5,
+	"company_name": 3,
+	"description":  1,
+}
+
+// JobRepository stores job postings
+type JobRepository interface {
+	Create(ctx context.Context, job *models.Job) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Job, error)
+	// Update sets the given fields if the job is still at version, bumps the
+	// version and updated_at, and returns the updated job
+	Update(ctx context.Context, id primitive.ObjectID, version int64, fields map[string]interface{}) (*models.Job, error)
+	// Delete removes the job if it is still at version
+	Delete(ctx context.Context, id primitive.ObjectID, version int64) error
+	// NormalizeSalaries recomputes the annual base-currency salaries of every job
+	// in a currency of the table, for when the rates change. Jobs without a
+	// currency or period are taken to be paid yearly in the base currency.
+	NormalizeSalaries(ctx context.Context, rates *currency.Rates) (int64, error)
+	// SyncCompany refreshes the copies of the company's name and logo on its jobs,
+	// moving the jobs it changes to a new version
+	SyncCompany(ctx context.Context, company *models.Company) (int64, error)
+	// ExpireOverdue moves published and paused jobs whose apply_by date is at or
+	// before now to expired, and returns how many it changed
+	ExpireOverdue(ctx context.Context, now time.Time) (int64, error)
+	// List returns one page of jobs matching the filter. With the relevance sort,
+	// each job's Score is set.
+	List(ctx context.Context, filter JobFilter, query JobQuery) ([]models.Job, error)
+	// Count returns the number of jobs matching the filter, counting no further than max when it is positive
+	Count(ctx context.Context, filter JobFilter, max int64) (int64, error)
+	// Facets counts the jobs matching the filter by each value of the given facets.
+	// A facet's counts ignore the facet's own selection, like an OR filter would.
+	Facets(ctx context.Context, filter JobFilter, facets []string) (map[string][]FacetCount, error)
+}
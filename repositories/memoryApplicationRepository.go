@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"job-portal/models"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ ApplicationRepository = (*MemoryApplicationRepository)(nil)
+
+// MemoryApplicationRepository keeps applications in memory. It is safe for concurrent use.
+type MemoryApplicationRepository struct {
+	mu           sync.RWMutex
+	applications map[primitive.ObjectID]*models.Application
+}
+
+// NewMemoryApplicationRepository creates an empty MemoryApplicationRepository
+func NewMemoryApplicationRepository() *MemoryApplicationRepository {
+	return &MemoryApplicationRepository{applications: map[primitive.ObjectID]*models.Application{}}
+}
+
+func (r *MemoryApplicationRepository) Create(ctx context.Context, application *models.Application) error {
+	stored, err := clone(application)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.applications[application.ID]; exists {
+		return fmt.Errorf("duplicate application ID %s", application.ID.Hex())
+	}
+	for _, existing := range r.applications {
+		if existing.JobID == application.JobID && existing.UserID == application.UserID {
+			return ErrDuplicate
+		}
+	}
+	r.applications[application.ID] = stored
+	return nil
+}
+
+func (r *MemoryApplicationRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Application, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	application, ok := r.applications[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return clone(application)
+}
+
+func (r *MemoryApplicationRepository) ListByJob(ctx context.Context, jobID primitive.ObjectID) ([]models.Application, error) {
+	return r.find(func(a *models.Application) bool { return a.JobID == jobID })
+}
+
+func (r *MemoryApplicationRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Application, error) {
+	return r.find(func(a *models.Application) bool { return a.UserID == userID })
+}
+
+func (r *MemoryApplicationRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, from string, change models.StatusChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	application, ok := r.applications[id]
+	if !ok || application.Status != from {
+		return ErrVersionConflict
+	}
+	application.Status = change.Status
+	application.UpdatedAt = change.ChangedAt
+	application.History = append(application.History, change)
+	return nil
+}
+
+func (r *MemoryApplicationRepository) find(match func(*models.Application) bool) ([]models.Application, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	applications := []models.Application{}
+	for _, application := range r.applications {
+		if !match(application) {
+			continue
+		}
+		out, err := clone(application)
+		if err != nil {
+			return nil, err
+		}
+		applications = append(applications, *out)
+	}
+	sort.Slice(applications, func(i, j int) bool {
+		return applications[i].CreatedAt.After(applications[j].CreatedAt)
+	})
+	return applications, nil
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"job-portal/models"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ CompanyRepository = (*MemoryCompanyRepository)(nil)
+
+// MemoryCompanyRepository keeps companies in memory. It is safe for concurrent use.
+type MemoryCompanyRepository struct {
+	mu        sync.RWMutex
+	companies map[primitive.ObjectID]*models.Company
+}
+
+// NewMemoryCompanyRepository creates an empty MemoryCompanyRepository
+func NewMemoryCompanyRepository() *MemoryCompanyRepository {
+	return &MemoryCompanyRepository{companies: map[primitive.ObjectID]*models.Company{}}
+}
+
+func (r *MemoryCompanyRepository) Create(ctx context.Context, company *models.Company) error {
+	stored, err := clone(company)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.companies[company.ID]; exists {
+		return fmt.Errorf("duplicate company ID %s", company.ID.Hex())
+	}
+	for _, existing := range r.companies {
+		if existing.Slug == company.Slug {
+			return ErrDuplicate
+		}
+	}
+	r.companies[company.ID] = stored
+	return nil
+}
+
+func (r *MemoryCompanyRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Company, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	company, ok := r.companies[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return clone(company)
+}
+
+func (r *MemoryCompanyRepository) FindBySlug(ctx context.Context, slug string) (*models.Company, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, company := range r.companies {
+		if company.Slug == slug {
+			return clone(company)
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryCompanyRepository) List(ctx context.Context, skip, limit int64) ([]models.Company, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := make([]*models.Company, 0, len(r.companies))
+	for _, company := range r.companies {
+		all = append(all, company)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Name != all[j].Name {
+			return all[i].Name < all[j].Name
+		}
+		return all[i].ID.Hex() < all[j].ID.Hex()
+	})
+
+	total := int64(len(all))
+	if skip > total {
+		skip = total
+	}
+	all = all[skip:]
+	if limit > 0 && int64(len(all)) > limit {
+		all = all[:limit]
+	}
+
+	companies := []models.Company{}
+	for _, company := range all {
+		copied, err := clone(company)
+		if err != nil {
+			return nil, 0, err
+		}
+		companies = append(companies, *copied)
+	}
+	return companies, total, nil
+}
+
+func (r *MemoryCompanyRepository) Update(ctx context.Context, id primitive.ObjectID, fields map[string]interface{}) (*models.Company, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	company, ok := r.companies[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	updated, err := applyFields(company, fields)
+	if err != nil {
+		return nil, err
+	}
+	r.companies[id] = updated
+	return clone(updated)
+}
+
+func (r *MemoryCompanyRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.companies[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.companies, id)
+	return nil
+}
@@ -0,0 +1,466 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"job-portal/currency"
+	"job-portal/geo"
+	"job-portal/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ JobRepository = (*MemoryJobRepository)(nil)
+
+// MemoryJobRepository keeps jobs in memory. It is safe for concurrent use and
+// filters the same way MongoJobRepository does, which makes it suitable for tests.
+type MemoryJobRepository struct {
+	mu    sync.RWMutex
+	jobs  map[primitive.ObjectID]*models.Job
+	order []primitive.ObjectID // Insertion order, like Mongo's natural order
+}
+
+// NewMemoryJobRepository creates an empty MemoryJobRepository
+func NewMemoryJobRepository() *MemoryJobRepository {
+	return &MemoryJobRepository{jobs: map[primitive.ObjectID]*models.Job{}}
+}
+
+func (r *MemoryJobRepository) Create(ctx context.Context, job *models.Job) error {
+	stored, err := clone(job)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.jobs[job.ID]; exists {
+		return fmt.Errorf("duplicate job ID %s", job.ID.Hex())
+	}
+	r.jobs[job.ID] = stored
+	r.order = append(r.order, job.ID)
+	return nil
+}
+
+func (r *MemoryJobRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return clone(job)
+}
+
+func (r *MemoryJobRepository) Update(ctx context.Context, id primitive.ObjectID, version int64, fields map[string]interface{}) (*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if job.Version != version {
+		return nil, ErrVersionConflict
+	}
+
+	updated, err := applyFields(job, fields)
+	if err != nil {
+		return nil, err
+	}
+	updated.Version++
+	updated.UpdatedAt = time.Now()
+	r.jobs[id] = updated
+	return clone(updated)
+}
+
+func (r *MemoryJobRepository) Delete(ctx context.Context, id primitive.ObjectID, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if job.Version != version {
+		return ErrVersionConflict
+	}
+	delete(r.jobs, id)
+	for i, existing := range r.order {
+		if existing == id {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *MemoryJobRepository) ExpireOverdue(ctx context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired int64
+	for _, job := range r.jobs {
+		if job.ApplyBy.IsZero() || job.ApplyBy.After(now) || !models.CanTransitionJob(job.CurrentStatus(), models.JobStatusExpired) {
+			continue
+		}
+		job.Status = models.JobStatusExpired
+		job.Version++
+		job.UpdatedAt = time.Now()
+		expired++
+	}
+	return expired, nil
+}
+
+func (r *MemoryJobRepository) NormalizeSalaries(ctx context.Context, rates *currency.Rates) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var modified int64
+	for _, job := range r.jobs {
+		code := job.Currency
+		if code == "" {
+			code = rates.Base
+		}
+		rate, ok := rates.Rate(code)
+		if !ok {
+			continue
+		}
+		period := job.Period
+		if _, ok := models.PeriodsPerYear[period]; !ok {
+			period = models.PeriodYear
+		}
+		perYear := models.PeriodsPerYear[period]
+		annualMin, annualMax := job.MinSalary*rate*perYear, job.MaxSalary*rate*perYear
+		if job.Currency == code && job.Period == period && job.AnnualMinSalary == annualMin && job.AnnualMaxSalary == annualMax {
+			continue
+		}
+		job.Currency, job.Period = code, period
+		job.AnnualMinSalary, job.AnnualMaxSalary = annualMin, annualMax
+		modified++
+	}
+	return modified, nil
+}
+
+func (r *MemoryJobRepository) SyncCompany(ctx context.Context, company *models.Company) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var modified int64
+	for _, job := range r.jobs {
+		if job.CompanyID != company.ID || (job.CompanyName == company.Name && job.CompanyLogo == company.Logo) {
+			continue
+		}
+		job.CompanyName, job.CompanyLogo = company.Name, company.Logo
+		job.UpdatedAt = time.Now()
+		job.Version++
+		modified++
+	}
+	return modified, nil
+}
+
+func (r *MemoryJobRepository) List(ctx context.Context, filter JobFilter, query JobQuery) ([]models.Job, error) {
+	matched, err := r.matching(filter)
+	if err != nil {
+		return nil, err
+	}
+	if query.Sort == SortRelevance {
+		text := ParseTextQuery(filter.Search)
+		for i := range matched {
+			matched[i].Score = float64(textScore(&matched[i], text))
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return positionLess(PositionOf(&matched[i], query.Sort), PositionOf(&matched[j], query.Sort), query.Sort)
+	})
+
+	start := int64(0)
+	if query.After != nil {
+		start = int64(sort.Search(len(matched), func(i int) bool {
+			return positionLess(*query.After, PositionOf(&matched[i], query.Sort), query.Sort)
+		}))
+	} else {
+		start = query.Skip
+	}
+	if start > int64(len(matched)) {
+		start = int64(len(matched))
+	}
+	matched = matched[start:]
+	if query.Limit > 0 && int64(len(matched)) > query.Limit {
+		matched = matched[:query.Limit]
+	}
+	return matched, nil
+}
+
+func (r *MemoryJobRepository) Count(ctx context.Context, filter JobFilter, max int64) (int64, error) {
+	match := jobMatcher(filter)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, id := range r.order {
+		if match(r.jobs[id]) {
+			count++
+			if max > 0 && count == max {
+				break
+			}
+		}
+	}
+	return count, nil
+}
+
+// matching returns copies of the jobs that match the filter, in insertion order
+func (r *MemoryJobRepository) matching(filter JobFilter) ([]models.Job, error) {
+	match := jobMatcher(filter)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	jobs := []models.Job{}
+	for _, id := range r.order {
+		if job := r.jobs[id]; match(job) {
+			copied, err := clone(job)
+			if err != nil {
+				return nil, err
+			}
+			jobs = append(jobs, *copied)
+		}
+	}
+	return jobs, nil
+}
+
+// positionLess reports whether position a comes before b in the sort order,
+// breaking ties by ID the way the Mongo sort does
+func positionLess(a, b JobPosition, order string) bool {
+	switch order {
+	case SortPostedAtAsc, SortPostedAtDesc:
+		if !a.PostedAt.Equal(b.PostedAt) {
+			return a.PostedAt.Before(b.PostedAt) == (order == SortPostedAtAsc)
+		}
+	case SortSalaryAsc: // A job without a salary has 0, as the Mongo sort reads it
+		if a.Value != b.Value {
+			return a.Value < b.Value
+		}
+	default:
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+	}
+	return a.ID.Hex() < b.ID.Hex()
+}
+
+func (r *MemoryJobRepository) Facets(ctx context.Context, filter JobFilter, facets []string) (map[string][]FacetCount, error) {
+	base, selections := splitFacetFilter(filter)
+	matchBase := jobMatcher(base)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := map[string][]FacetCount{}
+	for _, facet := range facets {
+		others := withoutFacet(selections, facet)
+		if facet == FacetSalaryBucket {
+			buckets := []FacetCount{}
+			for _, bucket := range SalaryBuckets {
+				match := jobMatcher(inBucket(others, bucket))
+				var count int64
+				for _, job := range r.jobs {
+					if matchBase(job) && match(job) {
+						count++
+					}
+				}
+				buckets = append(buckets, FacetCount{Value: bucket.Label, Count: count})
+			}
+			counts[facet] = buckets
+			continue
+		}
+
+		match := jobMatcher(others)
+		values := map[string]int64{}
+		for _, job := range r.jobs {
+			if matchBase(job) && match(job) {
+				values[facetValue(job, facet)]++
+			}
+		}
+		counts[facet] = enumFacetCounts(facet, values)
+	}
+	return counts, nil
+}
+
+// facetValue returns the job's value for an enum facet
+func facetValue(job *models.Job, facet string) string {
+	switch facet {
+	case FacetType:
+		return job.Type
+	case FacetWorkLocation:
+		return job.WorkLocation
+	case FacetExperience:
+		return job.Experience
+	case FacetEducation:
+		return job.Education
+	}
+	return ""
+}
+
+// jobMatcher evaluates a JobFilter the same way ApplyFilters' Mongo query does
+func jobMatcher(filter JobFilter) func(*models.Job) bool {
+	var search *TextQuery
+	if filter.Search != "" {
+		query := ParseTextQuery(filter.Search)
+		search = &query
+	}
+
+	return func(job *models.Job) bool {
+		if !filter.PostedAfter.IsZero() && job.PostedAt.Before(filter.PostedAfter) {
+			return false
+		}
+		if !filter.PostedBefore.IsZero() && !job.PostedAt.Before(filter.PostedBefore) {
+			return false
+		}
+		if !oneOf(job.Type, filter.Types) || !oneOf(job.Experience, filter.Experience) ||
+			!oneOf(job.Education, filter.Education) || !oneOf(job.WorkLocation, filter.WorkLocations) {
+			return false
+		}
+		if !hasSkills(job.Skills, filter.Skills, filter.AllSkills) {
+			return false
+		}
+		if !containsFold(job.Location, filter.Location) || !containsFold(job.CompanyName, filter.Company) {
+			return false
+		}
+		if filter.MinSalary != nil && job.AnnualMaxSalary < *filter.MinSalary {
+			return false
+		}
+		if filter.MaxSalary != nil && job.AnnualMinSalary > *filter.MaxSalary {
+			return false
+		}
+		if filter.Near != nil && !withinRadius(job, *filter.Near, filter.RadiusKm) &&
+			(filter.ExcludeRemote || job.WorkLocation != models.Remote) {
+			return false
+		}
+		if search != nil && !matchesText(job, *search) {
+			return false
+		}
+		if !filter.OpenAt.IsZero() && !job.IsOpen(filter.OpenAt) {
+			return false
+		}
+		if !filter.CreatedBy.IsZero() && job.CreatedBy != filter.CreatedBy {
+			return false
+		}
+		if !filter.CompanyID.IsZero() && job.CompanyID != filter.CompanyID {
+			return false
+		}
+		if !filter.OrgID.IsZero() && job.OrgID != filter.OrgID {
+			return false
+		}
+		if filter.Status != "" && job.Status != filter.Status {
+			return false
+		}
+		return true
+	}
+}
+
+// withinRadius reports whether the job has a geo point within radiusKm of near
+func withinRadius(job *models.Job, near geo.Point, radiusKm float64) bool {
+	if job.Geo == nil || len(job.Geo.Coordinates) != 2 {
+		return false
+	}
+	point := geo.Point{Lat: job.Geo.Coordinates[1], Lng: job.Geo.Coordinates[0]}
+	return geo.DistanceKm(point, near) <= radiusKm
+}
+
+// oneOf reports whether value is one of values, or values is empty
+func oneOf(value string, values []string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSkills reports whether the job requires any, or with all every, of the wanted skills
+func hasSkills(skills, wanted []string, all bool) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	for _, want := range wanted {
+		found := false
+		for _, skill := range skills {
+			found = found || strings.EqualFold(skill, want)
+		}
+		if found && !all {
+			return true
+		}
+		if !found && all {
+			return false
+		}
+	}
+	return all
+}
+
+// containsFold reports whether s contains substr, ignoring case
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// jobText returns the lower-cased text of each field in the job text index
+func jobText(job *models.Job) map[string]string {
+	return map[string]string{
+		"title":        strings.ToLower(job.Title),
+		"skills":       strings.ToLower(strings.Join(job.Skills, " ")),
+		"company_name": strings.ToLower(job.CompanyName),
+		"description":  strings.ToLower(job.Description),
+	}
+}
+
+// textScore approximates Mongo's $text: every phrase must appear, at least one term
+// must appear unless there are phrases, and no exclusion may appear. It returns
+// the sum of the weights of the fields each term or phrase was found in, or 0 for
+// no match. Unlike Mongo it does not stem words, so it matches substrings instead.
+func textScore(job *models.Job, query TextQuery) int {
+	text := jobText(job)
+	contains := func(token string) int {
+		score := 0
+		for field, value := range text {
+			if strings.Contains(value, strings.ToLower(token)) {
+				score += jobTextWeights[field]
+			}
+		}
+		return score
+	}
+
+	for _, token := range query.Excluded {
+		if contains(token) > 0 {
+			return 0
+		}
+	}
+	score := 0
+	for _, phrase := range query.Phrases {
+		found := contains(phrase)
+		if found == 0 {
+			return 0
+		}
+		score += found
+	}
+	for _, term := range query.Terms {
+		score += contains(term)
+	}
+	if !query.HasPositive() {
+		return 1 // Nothing asked for, nothing excluded
+	}
+	return score
+}
+
+func matchesText(job *models.Job, query TextQuery) bool {
+	return textScore(job, query) > 0
+}
@@ -0,0 +1,285 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"job-portal/models"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ OrganizationRepository = (*MemoryOrganizationRepository)(nil)
+
+// MemoryOrganizationRepository keeps organizations, memberships and invitations
+// in memory. It is safe for concurrent use.
+type MemoryOrganizationRepository struct {
+	mu          sync.RWMutex
+	orgs        map[primitive.ObjectID]*models.Organization
+	members     map[primitive.ObjectID]*models.Membership
+	invitations map[primitive.ObjectID]*models.Invitation
+}
+
+// NewMemoryOrganizationRepository creates an empty MemoryOrganizationRepository
+func NewMemoryOrganizationRepository() *MemoryOrganizationRepository {
+	return &MemoryOrganizationRepository{
+		orgs:        map[primitive.ObjectID]*models.Organization{},
+		members:     map[primitive.ObjectID]*models.Membership{},
+		invitations: map[primitive.ObjectID]*models.Invitation{},
+	}
+}
+
+func (r *MemoryOrganizationRepository) Create(ctx context.Context, org *models.Organization) error {
+	stored, err := clone(org)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.orgs[org.ID]; exists {
+		return fmt.Errorf("duplicate organization ID %s", org.ID.Hex())
+	}
+	r.orgs[org.ID] = stored
+	return nil
+}
+
+func (r *MemoryOrganizationRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Organization, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	org, ok := r.orgs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return clone(org)
+}
+
+func (r *MemoryOrganizationRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Organization, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	orgs := []models.Organization{}
+	for _, id := range ids {
+		if org, ok := r.orgs[id]; ok {
+			copied, err := clone(org)
+			if err != nil {
+				return nil, err
+			}
+			orgs = append(orgs, *copied)
+		}
+	}
+	sort.Slice(orgs, func(i, j int) bool {
+		if orgs[i].Name != orgs[j].Name {
+			return orgs[i].Name < orgs[j].Name
+		}
+		return orgs[i].ID.Hex() < orgs[j].ID.Hex()
+	})
+	return orgs, nil
+}
+
+func (r *MemoryOrganizationRepository) Update(ctx context.Context, id primitive.ObjectID, fields map[string]interface{}) (*models.Organization, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	org, ok := r.orgs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	updated, err := applyFields(org, fields)
+	if err != nil {
+		return nil, err
+	}
+	r.orgs[id] = updated
+	return clone(updated)
+}
+
+func (r *MemoryOrganizationRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.orgs[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.orgs, id)
+	for memberID, membership := range r.members {
+		if membership.OrgID == id {
+			delete(r.members, memberID)
+		}
+	}
+	for invitationID, invitation := range r.invitations {
+		if invitation.OrgID == id {
+			delete(r.invitations, invitationID)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryOrganizationRepository) AddMember(ctx context.Context, membership *models.Membership) error {
+	stored, err := clone(membership)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.members {
+		if existing.OrgID == membership.OrgID && existing.UserID == membership.UserID {
+			return ErrDuplicate
+		}
+	}
+	r.members[membership.ID] = stored
+	return nil
+}
+
+func (r *MemoryOrganizationRepository) FindMember(ctx context.Context, orgID, userID primitive.ObjectID) (*models.Membership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, membership := range r.members {
+		if membership.OrgID == orgID && membership.UserID == userID {
+			return clone(membership)
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryOrganizationRepository) ListMembers(ctx context.Context, orgID primitive.ObjectID) ([]models.Membership, error) {
+	return r.findMembers(func(m *models.Membership) bool { return m.OrgID == orgID })
+}
+
+func (r *MemoryOrganizationRepository) ListUserMemberships(ctx context.Context, userID primitive.ObjectID) ([]models.Membership, error) {
+	return r.findMembers(func(m *models.Membership) bool { return m.UserID == userID })
+}
+
+func (r *MemoryOrganizationRepository) SetMemberRole(ctx context.Context, membershipID primitive.ObjectID, role string, updatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	membership, ok := r.members[membershipID]
+	if !ok {
+		return ErrNotFound
+	}
+	membership.Role = role
+	membership.UpdatedAt = updatedAt
+	return nil
+}
+
+func (r *MemoryOrganizationRepository) RemoveMember(ctx context.Context, membershipID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.members[membershipID]; !ok {
+		return ErrNotFound
+	}
+	delete(r.members, membershipID)
+	return nil
+}
+
+func (r *MemoryOrganizationRepository) CreateInvitation(ctx context.Context, invitation *models.Invitation) error {
+	stored, err := clone(invitation)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, existing := range r.invitations {
+		if existing.OrgID == invitation.OrgID && existing.Email == invitation.Email && existing.AcceptedAt == nil {
+			delete(r.invitations, id)
+		}
+	}
+	r.invitations[invitation.ID] = stored
+	return nil
+}
+
+func (r *MemoryOrganizationRepository) ListPendingInvitations(ctx context.Context, orgID primitive.ObjectID, now time.Time) ([]models.Invitation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	invitations := []models.Invitation{}
+	for _, invitation := range r.invitations {
+		if invitation.OrgID == orgID && isPending(invitation, now) {
+			copied, err := clone(invitation)
+			if err != nil {
+				return nil, err
+			}
+			invitations = append(invitations, *copied)
+		}
+	}
+	sort.Slice(invitations, func(i, j int) bool {
+		return invitations[i].CreatedAt.After(invitations[j].CreatedAt)
+	})
+	return invitations, nil
+}
+
+func (r *MemoryOrganizationRepository) FindPendingInvitation(ctx context.Context, tokenHash string, now time.Time) (*models.Invitation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, invitation := range r.invitations {
+		if invitation.TokenHash == tokenHash && isPending(invitation, now) {
+			return clone(invitation)
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryOrganizationRepository) AcceptInvitation(ctx context.Context, id, userID primitive.ObjectID, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[id]
+	if !ok || !isPending(invitation, now) {
+		return ErrNotFound
+	}
+	invitation.AcceptedAt = &now
+	invitation.AcceptedBy = userID
+	return nil
+}
+
+func (r *MemoryOrganizationRepository) RevokeInvitation(ctx context.Context, orgID, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[id]
+	if !ok || invitation.OrgID != orgID || invitation.AcceptedAt != nil {
+		return ErrNotFound
+	}
+	delete(r.invitations, id)
+	return nil
+}
+
+// findMembers returns the memberships that match, oldest first like the Mongo sort
+func (r *MemoryOrganizationRepository) findMembers(match func(*models.Membership) bool) ([]models.Membership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	memberships := []models.Membership{}
+	for _, membership := range r.members {
+		if match(membership) {
+			copied, err := clone(membership)
+			if err != nil {
+				return nil, err
+			}
+			memberships = append(memberships, *copied)
+		}
+	}
+	sort.Slice(memberships, func(i, j int) bool {
+		if !memberships[i].CreatedAt.Equal(memberships[j].CreatedAt) {
+			return memberships[i].CreatedAt.Before(memberships[j].CreatedAt)
+		}
+		return memberships[i].ID.Hex() < memberships[j].ID.Hex()
+	})
+	return memberships, nil
+}
+
+// isPending reports whether an invitation is neither accepted nor expired at now
+func isPending(invitation *models.Invitation, now time.Time) bool {
+	return invitation.AcceptedAt == nil && invitation.ExpiresAt.After(now)
+}
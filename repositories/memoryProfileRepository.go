@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"job-portal/models"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ ProfileRepository = (*MemoryProfileRepository)(nil)
+
+// MemoryProfileRepository keeps profiles in memory, keyed by user. It is safe for concurrent use.
+type MemoryProfileRepository struct {
+	mu       sync.RWMutex
+	profiles map[primitive.ObjectID]*models.Profile
+}
+
+// NewMemoryProfileRepository creates an empty MemoryProfileRepository
+func NewMemoryProfileRepository() *MemoryProfileRepository {
+	return &MemoryProfileRepository{profiles: map[primitive.ObjectID]*models.Profile{}}
+}
+
+func (r *MemoryProfileRepository) FindByUserID(ctx context.Context, userID primitive.ObjectID) (*models.Profile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	profile, ok := r.profiles[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return clone(profile)
+}
+
+func (r *MemoryProfileRepository) Upsert(ctx context.Context, userID primitive.ObjectID, fields map[string]interface{}) (*models.Profile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	profile, ok := r.profiles[userID]
+	if !ok {
+		profile = &models.Profile{
+			ID:         primitive.NewObjectID(),
+			UserID:     userID,
+			Skills:     []string{},
+			Experience: []models.ExperienceEntry{},
+			Education:  []models.EducationEntry{},
+			CreatedAt:  time.Now(),
+		}
+	}
+
+	updated, err := applyFields(profile, fields)
+	if err != nil {
+		return nil, err
+	}
+	r.profiles[userID] = updated
+	return clone(updated)
+}
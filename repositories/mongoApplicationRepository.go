@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"job-portal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ApplicationRepository = (*MongoApplicationRepository)(nil)
+
+// MongoApplicationRepository stores applications in a Mongo collection with a
+// unique index on job_id and user_id
+type MongoApplicationRepository struct {
+	Collection *mongo.Collection
+}
+
+// NewMongoApplicationRepository creates a new instance of MongoApplicationRepository
+func NewMongoApplicationRepository(collection *mongo.Collection) *MongoApplicationRepository {
+	return &MongoApplicationRepository{Collection: collection}
+}
+
+func (r *MongoApplicationRepository) Create(ctx context.Context, application *models.Application) error {
+	_, err := r.Collection.InsertOne(ctx, application)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *MongoApplicationRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Application, error) {
+	var application models.Application
+	err := r.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&application)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &application, nil
+}
+
+func (r *MongoApplicationRepository) ListByJob(ctx context.Context, jobID primitive.ObjectID) ([]models.Application, error) {
+	return r.find(ctx, bson.M{"job_id": jobID})
+}
+
+func (r *MongoApplicationRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Application, error) {
+	return r.find(ctx, bson.M{"user_id": userID})
+}
+
+func (r *MongoApplicationRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, from string, change models.StatusChange) error {
+	// Match on the current status so concurrent reviewers cannot skip a step
+	filter := bson.M{"_id": id, "status": from}
+	update := bson.M{
+		"$set":  bson.M{"status": change.Status, "updated_at": change.ChangedAt},
+		"$push": bson.M{"history": change},
+	}
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *MongoApplicationRepository) find(ctx context.Context, filter bson.M) ([]models.Application, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	applications := []models.Application{}
+	if err := cursor.All(ctx, &applications); err != nil {
+		return nil, err
+	}
+	return applications, nil
+}
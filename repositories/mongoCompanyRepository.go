@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"job-portal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ CompanyRepository = (*MongoCompanyRepository)(nil)
+
+// MongoCompanyRepository stores companies in a Mongo collection
+type MongoCompanyRepository struct {
+	Collection *mongo.Collection
+}
+
+// NewMongoCompanyRepository creates a new instance of MongoCompanyRepository
+func NewMongoCompanyRepository(collection *mongo.Collection) *MongoCompanyRepository {
+	return &MongoCompanyRepository{Collection: collection}
+}
+
+func (r *MongoCompanyRepository) Create(ctx context.Context, company *models.Company) error {
+	_, err := r.Collection.InsertOne(ctx, company)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *MongoCompanyRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Company, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *MongoCompanyRepository) FindBySlug(ctx context.Context, slug string) (*models.Company, error) {
+	return r.findOne(ctx, bson.M{"slug": slug})
+}
+
+func (r *MongoCompanyRepository) List(ctx context.Context, skip, limit int64) ([]models.Company, int64, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}).SetSkip(skip).SetLimit(limit)
+	cursor, err := r.Collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	companies := []models.Company{}
+	if err := cursor.All(ctx, &companies); err != nil {
+		return nil, 0, err
+	}
+	total, err := r.Collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+	return companies, total, nil
+}
+
+func (r *MongoCompanyRepository) Update(ctx context.Context, id primitive.ObjectID, fields map[string]interface{}) (*models.Company, error) {
+	var company models.Company
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.Collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": fields}, opts).Decode(&company)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &company, nil
+}
+
+func (r *MongoCompanyRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.Collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoCompanyRepository) findOne(ctx context.Context, filter bson.M) (*models.Company, error) {
+	var company models.Company
+	err := r.Collection.FindOne(ctx, filter).Decode(&company)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &company, nil
+}
@@ -0,0 +1,448 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"job-portal/currency"
+	"job-portal/geo"
+	"job-portal/models"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ JobRepository = (*MongoJobRepository)(nil)
+
+// MongoJobRepository stores jobs in a Mongo collection
+type MongoJobRepository struct {
+	Collection *mongo.Collection
+}
+
+// NewMongoJobRepository creates a new instance of MongoJobRepository
+func NewMongoJobRepository(collection *mongo.Collection) *MongoJobRepository {
+	return &MongoJobRepository{Collection: collection}
+}
+
+func (r *MongoJobRepository) Create(ctx context.Context, job *models.Job) error {
+	_, err := r.Collection.InsertOne(ctx, job)
+	return err
+}
+
+func (r *MongoJobRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Job, error) {
+	var job models.Job
+	err := r.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&job)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *MongoJobRepository) Update(ctx context.Context, id primitive.ObjectID, version int64, fields map[string]interface{}) (*models.Job, error) {
+	update := bson.M{
+		"$set": fields,
+		"$inc": bson.M{"version": 1},
+		"$currentDate": bson.M{
+			"updated_at": true,
+		},
+	}
+
+	// The version in the filter makes the check and the write a single atomic step
+	var job models.Job
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.Collection.FindOneAndUpdate(ctx, versionFilter(id, version), update, opts).Decode(&job)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, r.missOrConflict(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *MongoJobRepository) Delete(ctx context.Context, id primitive.ObjectID, version int64) error {
+	result, err := r.Collection.DeleteOne(ctx, versionFilter(id, version))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return r.missOrConflict(ctx, id)
+	}
+	return nil
+}
+
+func (r *MongoJobRepository) ExpireOverdue(ctx context.Context, now time.Time) (int64, error) {
+	// A zero apply_by means no deadline; jobs without a status count as published
+	filter := bson.M{
+		"status":   bson.M{"$in": bson.A{models.JobStatusPublished, models.JobStatusPaused, nil}},
+		"apply_by": bson.M{"$gt": time.Time{}, "$lte": now},
+	}
+	update := bson.M{
+		"$set":         bson.M{"status": models.JobStatusExpired},
+		"$inc":         bson.M{"version": 1},
+		"$currentDate": bson.M{"updated_at": true},
+	}
+	result, err := r.Collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *MongoJobRepository) NormalizeSalaries(ctx context.Context, rates *currency.Rates) (int64, error) {
+	// Periods per year, looked up from each job's period with a year as the default
+	var branches bson.A
+	for period, perYear := range models.PeriodsPerYear {
+		branches = append(branches, bson.M{"case": bson.M{"$eq": bson.A{"$period", period}}, "then": perYear})
+	}
+	perYear := bson.M{"$switch": bson.M{"branches": branches, "default": 1}}
+
+	var modified int64
+	for _, code := range rates.Currencies() {
+		rate, _ := rates.Rate(code)
+		filter := bson.M{"currency": code}
+		if code == rates.Base {
+			filter = bson.M{"currency": bson.M{"$in": bson.A{code, nil, ""}}}
+		}
+		// An update pipeline, so the new values can be computed from each job's own fields
+		update := bson.A{bson.M{"$set": bson.M{
+			"currency":          code,
+			"period":            bson.M{"$cond": bson.A{bson.M{"$in": bson.A{"$period", models.SalaryPeriods}}, "$period", models.PeriodYear}},
+			"annual_min_salary": bson.M{"$multiply": bson.A{"$min_salary", rate, perYear}},
+			"annual_max_salary": bson.M{"$multiply": bson.A{"$max_salary", rate, perYear}},
+		}}}
+		result, err := r.Collection.UpdateMany(ctx, filter, update)
+		if err != nil {
+			return modified, err
+		}
+		modified += result.ModifiedCount
+	}
+	return modified, nil
+}
+
+func (r *MongoJobRepository) SyncCompany(ctx context.Context, company *models.Company) (int64, error) {
+	// Only jobs that change get a new version, so their ETags stop matching
+	filter := bson.M{"company_id": company.ID, "$or": bson.A{
+		bson.M{"company_name": bson.M{"$ne": company.Name}},
+		bson.M{"company_logo": bson.M{"$ne": company.Logo}},
+	}}
+	result, err := r.Collection.UpdateMany(ctx, filter, bson.M{
+		"$set": bson.M{"company_name": company.Name, "company_logo": company.Logo, "updated_at": time.Now()},
+		"$inc": bson.M{"version": 1},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// versionFilter matches the job only at the given version. Jobs stored before
+// versioning have no version field and count as version 0.
+func versionFilter(id primitive.ObjectID, version int64) bson.M {
+	if version == 0 {
+		return bson.M{"_id": id, "version": bson.M{"$in": bson.A{0, nil}}}
+	}
+	return bson.M{"_id": id, "version": version}
+}
+
+// missOrConflict tells apart a job that is gone from one that has moved on to another version
+func (r *MongoJobRepository) missOrConflict(ctx context.Context, id primitive.ObjectID) error {
+	count, err := r.Collection.CountDocuments(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return ErrVersionConflict
+}
+
+func (r *MongoJobRepository) List(ctx context.Context, filter JobFilter, query JobQuery) ([]models.Job, error) {
+	field, direction := sortKey(query.Sort)
+
+	// The text score only exists inside a search, so relevance goes through an
+	// aggregation where the score becomes a field that can be sorted and paged on
+	pipeline := bson.A{bson.M{"$match": ApplyFilters(filter, bson.M{})}}
+	if query.Sort == SortRelevance {
+		pipeline = append(pipeline, bson.M{"$addFields": bson.M{"score": bson.M{"$meta": "textScore"}}})
+	}
+	// Jobs saved without a salary have no annual_max_salary. They sort as 0, the
+	// value they decode to and so carry in their position; comparing a cursor
+	// with the missing field itself would skip them after the first page.
+	if field == "annual_max_salary" {
+		pipeline = append(pipeline, bson.M{"$addFields": bson.M{salarySortField: bson.M{"$ifNull": bson.A{"$annual_max_salary", 0}}}})
+		field = salarySortField
+	}
+	if query.After != nil {
+		var value interface{} = query.After.Value
+		if field == "posted_at" {
+			value = query.After.PostedAt
+		}
+		comparison := "$gt"
+		if direction < 0 {
+			comparison = "$lt"
+		}
+		pipeline = append(pipeline, bson.M{"$match": bson.M{"$or": bson.A{
+			bson.M{field: bson.M{comparison: value}},
+			bson.M{field: value, "_id": bson.M{"$gt": query.After.ID}},
+		}}})
+	}
+	pipeline = append(pipeline, bson.M{"$sort": bson.D{{Key: field, Value: direction}, {Key: "_id", Value: 1}}})
+	if query.After == nil && query.Skip > 0 {
+		pipeline = append(pipeline, bson.M{"$skip": query.Skip})
+	}
+	if query.Limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": query.Limit})
+	}
+	if field == salarySortField {
+		pipeline = append(pipeline, bson.M{"$unset": salarySortField})
+	}
+
+	cursor, err := r.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	jobs := []models.Job{}
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *MongoJobRepository) Count(ctx context.Context, filter JobFilter, max int64) (int64, error) {
+	opts := options.Count()
+	if max > 0 {
+		opts.SetLimit(max)
+	}
+	return r.Collection.CountDocuments(ctx, ApplyFilters(filter, bson.M{}), opts)
+}
+
+// salarySortField holds annual_max_salary with a missing salary read as 0 while sorting by salary
+const salarySortField = "salary_sort"
+
+// sortKey returns the stored field and direction of a sort order
+func sortKey(sort string) (string, int) {
+	switch sort {
+	case SortPostedAtAsc:
+		return "posted_at", 1
+	case SortSalaryAsc:
+		return "annual_max_salary", 1
+	case SortSalaryDesc:
+		return "annual_max_salary", -1
+	case SortRelevance:
+		return "score", -1
+	}
+	return "posted_at", -1
+}
+
+func (r *MongoJobRepository) Facets(ctx context.Context, filter JobFilter, facets []string) (map[string][]FacetCount, error) {
+	base, selections := splitFacetFilter(filter)
+
+	// The shared conditions, $text included, must come first; each facet then
+	// applies the other facets' selections in its own branch of $facet
+	branches := bson.M{}
+	for _, facet := range facets {
+		others := withoutFacet(selections, facet)
+		if facet == FacetSalaryBucket {
+			for i, bucket := range SalaryBuckets {
+				branches[fmt.Sprintf("%s_%d", facet, i)] = bson.A{
+					bson.M{"$match": ApplyFilters(inBucket(others, bucket), nil)},
+					bson.M{"$count": "count"},
+				}
+			}
+			continue
+		}
+		branches[facet] = bson.A{
+			bson.M{"$match": ApplyFilters(others, nil)},
+			bson.M{"$group": bson.M{"_id": "$" + facet, "count": bson.M{"$sum": 1}}},
+		}
+	}
+	if len(branches) == 0 {
+		return map[string][]FacetCount{}, nil
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": ApplyFilters(base, nil)},
+		bson.M{"$facet": branches},
+	}
+	cursor, err := r.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []map[string][]struct {
+		ID    interface{} `bson:"_id"`
+		Count int64       `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return map[string][]FacetCount{}, nil
+	}
+	result := results[0]
+
+	counts := map[string][]FacetCount{}
+	for _, facet := range facets {
+		if facet == FacetSalaryBucket {
+			buckets := []FacetCount{}
+			for i, bucket := range SalaryBuckets {
+				var count int64
+				if rows := result[fmt.Sprintf("%s_%d", facet, i)]; len(rows) > 0 {
+					count = rows[0].Count
+				}
+				buckets = append(buckets, FacetCount{Value: bucket.Label, Count: count})
+			}
+			counts[facet] = buckets
+			continue
+		}
+		values := map[string]int64{}
+		for _, row := range result[facet] {
+			if value, ok := row.ID.(string); ok {
+				values[value] = row.Count
+			}
+		}
+		counts[facet] = enumFacetCounts(facet, values)
+	}
+	return counts, nil
+}
+
+// ApplyFilters adds the conditions of a JobFilter to a Mongo query
+func ApplyFilters(filter JobFilter, existingFilter bson.M) bson.M {
+	// Initialize the filter if it doesn't exist
+	if existingFilter == nil {
+		existingFilter = bson.M{}
+	}
+
+	// Date posted filter
+	var and []bson.M
+	if !filter.PostedAfter.IsZero() {
+		and = append(and, bson.M{"posted_at": bson.M{"$gte": filter.PostedAfter}})
+	}
+	if !filter.PostedBefore.IsZero() {
+		and = append(and, bson.M{"posted_at": bson.M{"$lt": filter.PostedBefore}})
+	}
+
+	// Enum filters (job type, experience, education, work location): any of the values
+	for field, values := range map[string][]string{
+		"type":          filter.Types,
+		"experience":    filter.Experience,
+		"education":     filter.Education,
+		"work_location": filter.WorkLocations,
+	} {
+		if len(values) > 0 {
+			existingFilter[field] = bson.M{"$in": values}
+		}
+	}
+
+	// Skills filter: whole skills, ignoring case
+	if len(filter.Skills) > 0 {
+		var skills bson.A
+		for _, skill := range filter.Skills {
+			pattern := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(skill) + "$", Options: "i"}
+			if filter.AllSkills {
+				and = append(and, bson.M{"skills": pattern})
+			} else {
+				skills = append(skills, pattern)
+			}
+		}
+		if !filter.AllSkills {
+			and = append(and, bson.M{"skills": bson.M{"$in": skills}})
+		}
+	}
+
+	// Location and company filters: substrings, ignoring case
+	if filter.Location != "" {
+		existingFilter["location"] = bson.M{"$regex": regexp.QuoteMeta(filter.Location), "$options": "i"}
+	}
+	if filter.Company != "" {
+		existingFilter["company_name"] = bson.M{"$regex": regexp.QuoteMeta(filter.Company), "$options": "i"}
+	}
+
+	// Salary Range filter: the job's range must overlap the requested range
+	// compared as annual amounts in the base currency
+	if filter.MinSalary != nil {
+		and = append(and, bson.M{"annual_max_salary": bson.M{"$gte": *filter.MinSalary}})
+	}
+	if filter.MaxSalary != nil {
+		and = append(and, bson.M{"annual_min_salary": bson.M{"$lte": *filter.MaxSalary}})
+	}
+
+	// Radius filter on the 2dsphere-indexed geo point. Remote jobs can be done
+	// from anywhere, so they match wherever they are unless excluded.
+	if filter.Near != nil {
+		within := bson.M{"geo": bson.M{"$geoWithin": bson.M{"$centerSphere": bson.A{
+			bson.A{filter.Near.Lng, filter.Near.Lat},
+			filter.RadiusKm / geo.EarthRadiusKm,
+		}}}}
+		if filter.ExcludeRemote {
+			and = append(and, within)
+		} else {
+			and = append(and, bson.M{"$or": bson.A{within, bson.M{"work_location": models.Remote}}})
+		}
+	}
+
+	// Public visibility, see models.Job.IsOpen. Jobs stored before statuses
+	// existed have none and count as published; a zero apply_by means no deadline.
+	if !filter.OpenAt.IsZero() {
+		and = append(and,
+			bson.M{"status": bson.M{"$in": bson.A{models.JobStatusPublished, nil}}},
+			bson.M{"posted_at": bson.M{"$lte": filter.OpenAt}},
+			bson.M{"$or": bson.A{
+				bson.M{"apply_by": bson.M{"$gt": filter.OpenAt}},
+				bson.M{"apply_by": bson.M{"$lte": time.Time{}}},
+				bson.M{"apply_by": nil},
+			}},
+		)
+	}
+	if len(and) > 0 {
+		existingFilter["$and"] = and
+	}
+
+	// Owner and status filters
+	if !filter.CreatedBy.IsZero() {
+		existingFilter["created_by"] = filter.CreatedBy
+	}
+	if !filter.CompanyID.IsZero() {
+		existingFilter["company_id"] = filter.CompanyID
+	}
+	if !filter.OrgID.IsZero() {
+		existingFilter["org_id"] = filter.OrgID
+	}
+	if filter.Status != "" {
+		existingFilter["status"] = filter.Status
+	}
+
+	// Full-text search through the weighted text index. $text understands phrases
+	// and exclusions itself, but matches nothing when a query only excludes, so
+	// that case falls back to escaped regexes.
+	if filter.Search != "" {
+		query := ParseTextQuery(filter.Search)
+		if query.HasPositive() {
+			existingFilter["$text"] = bson.M{"$search": filter.Search}
+		} else {
+			var excluded bson.A
+			for _, term := range query.Excluded {
+				pattern := bson.M{"$regex": regexp.QuoteMeta(term), "$options": "i"}
+				for field := range jobTextWeights {
+					excluded = append(excluded, bson.M{field: pattern})
+				}
+			}
+			if len(excluded) > 0 {
+				existingFilter["$nor"] = excluded
+			}
+		}
+	}
+
+	return existingFilter
+}
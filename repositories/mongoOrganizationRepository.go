@@ -0,0 +1,226 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"job-portal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ OrganizationRepository = (*MongoOrganizationRepository)(nil)
+
+// MongoOrganizationRepository keeps organizations, memberships and invitations
+// in three Mongo collections
+type MongoOrganizationRepository struct {
+	Collection  *mongo.Collection
+	Members     *mongo.Collection // Unique index on org_id and user_id
+	Invitations *mongo.Collection
+}
+
+// NewMongoOrganizationRepository creates a new instance of MongoOrganizationRepository
+func NewMongoOrganizationRepository(collection, members, invitations *mongo.Collection) *MongoOrganizationRepository {
+	return &MongoOrganizationRepository{Collection: collection, Members: members, Invitations: invitations}
+}
+
+func (r *MongoOrganizationRepository) Create(ctx context.Context, org *models.Organization) error {
+	_, err := r.Collection.InsertOne(ctx, org)
+	return err
+}
+
+func (r *MongoOrganizationRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Organization, error) {
+	var org models.Organization
+	err := r.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&org)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *MongoOrganizationRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Organization, error) {
+	orgs := []models.Organization{}
+	if len(ids) == 0 {
+		return orgs, nil
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.Collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &orgs); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+func (r *MongoOrganizationRepository) Update(ctx context.Context, id primitive.ObjectID, fields map[string]interface{}) (*models.Organization, error) {
+	var org models.Organization
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.Collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": fields}, opts).Decode(&org)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *MongoOrganizationRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.Collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	if _, err := r.Members.DeleteMany(ctx, bson.M{"org_id": id}); err != nil {
+		return err
+	}
+	_, err = r.Invitations.DeleteMany(ctx, bson.M{"org_id": id})
+	return err
+}
+
+func (r *MongoOrganizationRepository) AddMember(ctx context.Context, membership *models.Membership) error {
+	_, err := r.Members.InsertOne(ctx, membership)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *MongoOrganizationRepository) FindMember(ctx context.Context, orgID, userID primitive.ObjectID) (*models.Membership, error) {
+	var membership models.Membership
+	err := r.Members.FindOne(ctx, bson.M{"org_id": orgID, "user_id": userID}).Decode(&membership)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+func (r *MongoOrganizationRepository) ListMembers(ctx context.Context, orgID primitive.ObjectID) ([]models.Membership, error) {
+	return r.findMembers(ctx, bson.M{"org_id": orgID})
+}
+
+func (r *MongoOrganizationRepository) ListUserMemberships(ctx context.Context, userID primitive.ObjectID) ([]models.Membership, error) {
+	return r.findMembers(ctx, bson.M{"user_id": userID})
+}
+
+func (r *MongoOrganizationRepository) SetMemberRole(ctx context.Context, membershipID primitive.ObjectID, role string, updatedAt time.Time) error {
+	result, err := r.Members.UpdateOne(ctx, bson.M{"_id": membershipID}, bson.M{"$set": bson.M{"role": role, "updated_at": updatedAt}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoOrganizationRepository) RemoveMember(ctx context.Context, membershipID primitive.ObjectID) error {
+	result, err := r.Members.DeleteOne(ctx, bson.M{"_id": membershipID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoOrganizationRepository) CreateInvitation(ctx context.Context, invitation *models.Invitation) error {
+	replaced := bson.M{"org_id": invitation.OrgID, "email": invitation.Email, "accepted_at": bson.M{"$exists": false}}
+	if _, err := r.Invitations.DeleteMany(ctx, replaced); err != nil {
+		return err
+	}
+	_, err := r.Invitations.InsertOne(ctx, invitation)
+	return err
+}
+
+func (r *MongoOrganizationRepository) ListPendingInvitations(ctx context.Context, orgID primitive.ObjectID, now time.Time) ([]models.Invitation, error) {
+	filter := pendingInvitation(now)
+	filter["org_id"] = orgID
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.Invitations.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	invitations := []models.Invitation{}
+	if err := cursor.All(ctx, &invitations); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *MongoOrganizationRepository) FindPendingInvitation(ctx context.Context, tokenHash string, now time.Time) (*models.Invitation, error) {
+	filter := pendingInvitation(now)
+	filter["token_hash"] = tokenHash
+	var invitation models.Invitation
+	err := r.Invitations.FindOne(ctx, filter).Decode(&invitation)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *MongoOrganizationRepository) AcceptInvitation(ctx context.Context, id, userID primitive.ObjectID, now time.Time) error {
+	// Matching on the pending state makes acceptance single-use under concurrency
+	filter := pendingInvitation(now)
+	filter["_id"] = id
+	result, err := r.Invitations.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"accepted_at": now, "accepted_by": userID}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoOrganizationRepository) RevokeInvitation(ctx context.Context, orgID, id primitive.ObjectID) error {
+	result, err := r.Invitations.DeleteOne(ctx, bson.M{"_id": id, "org_id": orgID, "accepted_at": bson.M{"$exists": false}})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoOrganizationRepository) findMembers(ctx context.Context, filter bson.M) ([]models.Membership, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.Members.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	memberships := []models.Membership{}
+	if err := cursor.All(ctx, &memberships); err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+// pendingInvitation matches invitations that are neither accepted nor expired at now
+func pendingInvitation(now time.Time) bson.M {
+	return bson.M{"accepted_at": bson.M{"$exists": false}, "expires_at": bson.M{"$gt": now}}
+}
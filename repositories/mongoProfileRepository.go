@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"job-portal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ProfileRepository = (*MongoProfileRepository)(nil)
+
+// MongoProfileRepository stores profiles in a Mongo collection with a unique index on user_id
+type MongoProfileRepository struct {
+	Collection *mongo.Collection
+}
+
+// NewMongoProfileRepository creates a new instance of MongoProfileRepository
+func NewMongoProfileRepository(collection *mongo.Collection) *MongoProfileRepository {
+	return &MongoProfileRepository{Collection: collection}
+}
+
+func (r *MongoProfileRepository) FindByUserID(ctx context.Context, userID primitive.ObjectID) (*models.Profile, error) {
+	var profile models.Profile
+	err := r.Collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&profile)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *MongoProfileRepository) Upsert(ctx context.Context, userID primitive.ObjectID, fields map[string]interface{}) (*models.Profile, error) {
+	insert := bson.M{"_id": primitive.NewObjectID(), "user_id": userID, "created_at": time.Now()}
+	for _, key := range []string{"skills", "experience", "education"} {
+		if _, ok := fields[key]; !ok {
+			insert[key] = bson.A{} // A profile first created by a resume upload
+		}
+	}
+	update := bson.M{"$set": fields, "$setOnInsert": insert}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var profile models.Profile
+	err := r.Collection.FindOneAndUpdate(ctx, bson.M{"user_id": userID}, update, opts).Decode(&profile)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent first save created the profile; this update now matches it
+		err = r.Collection.FindOneAndUpdate(ctx, bson.M{"user_id": userID}, update, opts).Decode(&profile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"job-portal/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OrganizationRepository stores organizations with their memberships and invitations
+type OrganizationRepository interface {
+	Create(ctx context.Context, org *models.Organization) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Organization, error)
+	// FindByIDs returns the organizations with the given IDs, ordered by name
+	FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.Organization, error)
+	// Update sets the given fields and returns the updated organization
+	Update(ctx context.Context, id primitive.ObjectID, fields map[string]interface{}) (*models.Organization, error)
+	// Delete removes the organization with its memberships and invitations
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	// AddMember stores a membership, failing with ErrDuplicate if the user already belongs to the organization
+	AddMember(ctx context.Context, membership *models.Membership) error
+	FindMember(ctx context.Context, orgID, userID primitive.ObjectID) (*models.Membership, error)
+	// ListMembers returns the memberships of an organization, oldest first
+	ListMembers(ctx context.Context, orgID primitive.ObjectID) ([]models.Membership, error)
+	// ListUserMemberships returns the memberships of a user, oldest first
+	ListUserMemberships(ctx context.Context, userID primitive.ObjectID) ([]models.Membership, error)
+	SetMemberRole(ctx context.Context, membershipID primitive.ObjectID, role string, updatedAt time.Time) error
+	RemoveMember(ctx context.Context, membershipID primitive.ObjectID) error
+
+	// CreateInvitation stores an invitation, replacing the pending invitations
+	// to the same email address in the same organization
+	CreateInvitation(ctx context.Context, invitation *models.Invitation) error
+	// ListPendingInvitations returns the invitations of an organization that are
+	// neither accepted nor expired at now, newest first
+	ListPendingInvitations(ctx context.Context, orgID primitive.ObjectID, now time.Time) ([]models.Invitation, error)
+	// FindPendingInvitation finds the invitation with the token hash if it is neither accepted nor expired at now
+	FindPendingInvitation(ctx context.Context, tokenHash string, now time.Time) (*models.Invitation, error)
+	// AcceptInvitation marks a pending invitation as accepted by the user. It
+	// fails with ErrNotFound if the invitation is no longer pending, so each
+	// invitation is accepted at most once.
+	AcceptInvitation(ctx context.Context, id, userID primitive.ObjectID, now time.Time) error
+	// RevokeInvitation deletes a pending invitation of the organization
+	RevokeInvitation(ctx context.Context, orgID, id primitive.ObjectID) error
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+	"job-portal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProfileRepository stores candidate profiles, at most one per user
+type ProfileRepository interface {
+	FindByUserID(ctx context.Context, userID primitive.ObjectID) (*models.Profile, error)
+	// Upsert sets the given fields on the user's profile, creating the profile
+	// with empty lists first if the user has none, and returns it
+	Upsert(ctx context.Context, userID primitive.ObjectID, fields map[string]interface{}) (*models.Profile, error)
+}
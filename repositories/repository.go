@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrNotFound is returned when no document matches the lookup
+var ErrNotFound = errors.New("document not found")
+
+// ErrDuplicate is returned when a write would break a unique index, such as the one on users.email
+var ErrDuplicate = errors.New("duplicate key")
+
+// ErrVersionConflict is returned when a document changed since the version the caller read
+var ErrVersionConflict = errors.New("document was modified concurrently")
+
+// clone returns a deep copy of v through a BSON round trip, so in-memory
+// repositories hand out fresh values the same way Mongo decodes fresh documents
+func clone[T any](v *T) (*T, error) {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out T
+	if err := bson.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// applyFields sets top-level fields on v the way a Mongo $set would
+func applyFields[T any](v *T, fields map[string]interface{}) (*T, error) {
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	doc := bson.M{}
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	for key, value := range fields {
+		doc[key] = value
+	}
+
+	data, err = bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var out T
+	if err := bson.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TextQuery is a search string split the way Mongo's $text reads it: plain
+// terms, "quoted phrases", and -excluded terms or -"excluded phrases"
+type TextQuery struct {
+	Terms    []string
+	Phrases  []string
+	Excluded []string
+}
+
+// ParseTextQuery splits a search string into its terms, phrases and exclusions
+func ParseTextQuery(search string) TextQuery {
+	var query TextQuery
+	for rest := strings.TrimSpace(search); rest != ""; rest = strings.TrimLeftFunc(rest, unicode.IsSpace) {
+		excluded := false
+		if rest[0] == '-' {
+			excluded = true
+			rest = rest[1:]
+		}
+
+		var token string
+		isPhrase := strings.HasPrefix(rest, `"`)
+		if isPhrase {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				token, rest = rest[1:], "" // Unterminated phrase runs to the end
+			} else {
+				token, rest = rest[1:end+1], rest[end+2:]
+			}
+			token = strings.TrimSpace(token)
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			token, rest = rest[:end], rest[end:]
+		}
+		if token == "" {
+			continue
+		}
+
+		switch {
+		case excluded:
+			query.Excluded = append(query.Excluded, token)
+		case isPhrase:
+			query.Phrases = append(query.Phrases, token)
+		default:
+			query.Terms = append(query.Terms, token)
+		}
+	}
+	return query
+}
+
+// HasPositive reports whether the query asks for something, rather than only excluding
+func (q TextQuery) HasPositive() bool {
+	return len(q.Terms) > 0 || len(q.Phrases) > 0
+}
@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTextQuery(t *testing.T) {
+	tests := []struct {
+		search string
+		want   TextQuery
+	}{
+		{"golang developer", TextQuery{Terms: []string{"golang", "developer"}}},
+		{`"senior engineer" remote`, TextQuery{Terms: []string{"remote"}, Phrases: []string{"senior engineer"}}},
+		{`go -java -"project manager"`, TextQuery{Terms: []string{"go"}, Excluded: []string{"java", "project manager"}}},
+		{`"unterminated phrase`, TextQuery{Phrases: []string{"unterminated phrase"}}},
+		{`go "ab`, TextQuery{Terms: []string{"go"}, Phrases: []string{"ab"}}},
+		{`"" - "  "`, TextQuery{}},
+		{"   ", TextQuery{}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseTextQuery(tt.search); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTextQuery(%q) = %+v, want %+v", tt.search, got, tt.want)
+		}
+	}
+}
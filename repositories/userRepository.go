@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"job-portal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserRepository stores user accounts
+type UserRepository interface {
+	// Create stores a new user, failing with ErrDuplicate if the email is taken
+	Create(ctx context.Context, user *models.User) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// Update sets the given fields and returns the updated user
+	Update(ctx context.Context, id primitive.ObjectID, fields map[string]interface{}) (*models.User, error)
+}
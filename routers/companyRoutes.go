@@ -0,0 +1,25 @@
+package routers
+
+import (
+	"job-portal/controllers"
+	"job-portal/middlewares"
+	"job-portal/policy"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Changing a company needs company:update:own or company:update:any, which the company service checks against the company itself
+func RegisterCompanyRoutes(e *echo.Echo, companyController *controllers.CompanyController, config Config) {
+	authenticated := middlewares.JWTMiddleware(config.JWT)
+	verified := middlewares.RequireVerifiedEmail(config.VerifiedEmail)
+	activeOrg := middlewares.ActiveOrg(config.Org) // The organization is optional, picked by the X-Org-ID header
+
+	companyGroup := e.Group("/companies")
+
+	companyGroup.GET("", companyController.ListCompaniesHandler)                                                                                           // List companies
+	companyGroup.POST("", companyController.CreateCompanyHandler, authenticated, activeOrg, middlewares.RequirePermission(policy.CompanyCreate), verified) // Create a company
+	companyGroup.GET("/:slug", companyController.GetCompanyHandler)                                                                                        // Get a company and its open jobs
+	companyGroup.PUT("/:slug", companyController.UpdateCompanyHandler, authenticated, verified)                                                            // Update a company
+	companyGroup.DELETE("/:slug", companyController.DeleteCompanyHandler, authenticated, verified)                                                         // Delete a company without jobs
+	companyGroup.PUT("/:slug/verification", companyController.SetVerifiedHandler, authenticated, middlewares.RequirePermission(policy.CompanyVerify))      // Mark a company as verified
+}
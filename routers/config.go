@@ -0,0 +1,10 @@
+package routers
+
+import "job-portal/middlewares"
+
+// Config holds the settings of the middlewares that guard the routes
+type Config struct {
+	JWT           middlewares.JWTConfig
+	VerifiedEmail middlewares.VerifiedEmailConfig
+	Org           middlewares.OrgConfig
+}
@@ -3,16 +3,39 @@ package routers
 import (
 	"job-portal/controllers"
 	"job-portal/middlewares"
+	"job-portal/policy"
 
 	"github.com/labstack/echo/v4"
 )
 
-func RegisterJobRoutes(e *echo.Echo, jobController *controllers.JobController) {
+// Changing a job needs job:update:own or job:update:any, which the job service checks against the job itself
+func RegisterJobRoutes(e *echo.Echo, jobController *controllers.JobController, applicationController *controllers.ApplicationController, config Config) {
+	authenticated := middlewares.JWTMiddleware(config.JWT)
+	verified := middlewares.RequireVerifiedEmail(config.VerifiedEmail)
+	activeOrg := middlewares.ActiveOrg(config.Org) // The organization is optional, picked by the X-Org-ID header
+
 	jobGroup := e.Group("/jobs")
 
-	jobGroup.POST("/create", jobController.CreateJobHandler, middlewares.JWTMiddleware("user", "admin"))
-	jobGroup.GET("", jobController.ListJobsHandler)                                                     // Get all jobs
-	jobGroup.GET("/:id", jobController.GetJobHandler, middlewares.JWTMiddleware("user", "admin"))       // Get a job by ID
-	jobGroup.PATCH("/:id", jobController.UpdateJobHandler, middlewares.JWTMiddleware("user", "admin"))  // Update a job by ID
-	jobGroup.DELETE("/:id", jobController.DeleteJobHandler, middlewares.JWTMiddleware("user", "admin")) // Delete a job by ID
+	jobGroup.POST("/create", jobController.CreateJobHandler, authenticated, activeOrg, middlewares.RequirePermission(policy.JobCreate), verified)
+	jobGroup.GET("", jobController.ListJobsHandler)                                  // Get all jobs
+	jobGroup.GET("/:id", jobController.GetJobHandler, authenticated)                 // Get a job by ID
+	jobGroup.PATCH("/:id", jobController.UpdateJobHandler, authenticated, verified)  // Update a job by ID
+	jobGroup.DELETE("/:id", jobController.DeleteJobHandler, authenticated, verified) // Delete a job by ID
+
+	// Lifecycle
+	jobGroup.POST("/:id/publish", jobController.PublishJobHandler, authenticated, verified) // Publish now or schedule
+	jobGroup.POST("/:id/pause", jobController.PauseJobHandler, authenticated, verified)     // Take out of listings
+	jobGroup.POST("/:id/close", jobController.CloseJobHandler, authenticated, verified)     // Stop taking applications
+
+	// Applications; reviewing needs application:review through the role or the job's organization, which the application service checks
+	jobGroup.POST("/:id/apply", applicationController.ApplyHandler, authenticated, middlewares.RequirePermission(policy.ApplicationCreate), verified) // Apply to a job
+	jobGroup.GET("/:id/applications", applicationController.ListJobApplicationsHandler, authenticated)                                                // List applications for a job
+	jobGroup.GET("/:id/applications/:applicationId", applicationController.GetApplicationHandler, authenticated)                                      // Get an application with the applicant's profile
+	jobGroup.GET("/:id/applications/:applicationId/resume", applicationController.ApplicantResumeHandler, authenticated)                              // Download the applicant's resume
+	jobGroup.PATCH("/:id/applications/:applicationId", applicationController.UpdateApplicationStatusHandler, authenticated)                           // Move an application along the pipeline
+
+	meGroup := e.Group("/me")
+	meGroup.GET("/jobs", jobController.MyJobsHandler, authenticated, activeOrg)                                 // List my jobs, drafts included
+	meGroup.GET("/applications", applicationController.MyApplicationsHandler, authenticated)                    // List my applications
+	meGroup.POST("/applications/:id/withdraw", applicationController.WithdrawApplicationHandler, authenticated) // Withdraw one of my applications
 }
@@ -0,0 +1,11 @@
+package routers
+
+import (
+	"job-portal/controllers"
+
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterKeyRoutes(e *echo.Echo, keyController *controllers.KeyController) {
+	e.GET("/.well-known/jwks.json", keyController.JWKSHandler)
+}
@@ -0,0 +1,35 @@
+package routers
+
+import (
+	"job-portal/controllers"
+	"job-portal/middlewares"
+	"job-portal/models"
+	"job-portal/policy"
+
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterOrganizationRoutes(e *echo.Echo, organizationController *controllers.OrganizationController, config Config) {
+	authenticated := middlewares.JWTMiddleware(config.JWT)
+	verified := middlewares.RequireVerifiedEmail(config.VerifiedEmail)
+
+	orgGroup := e.Group("/orgs", authenticated)
+
+	// ActiveOrg checks the membership in :orgId and the organization role
+	anyMember := middlewares.ActiveOrg(config.Org, models.OrgRoleOwner, models.OrgRoleAdmin, models.OrgRoleMember)
+	managers := middlewares.ActiveOrg(config.Org, models.OrgRoleOwner, models.OrgRoleAdmin)
+	owner := middlewares.ActiveOrg(config.Org, models.OrgRoleOwner)
+
+	orgGroup.POST("", organizationController.CreateOrganizationHandler, middlewares.RequirePermission(policy.OrgCreate), verified) // Create an organization
+	orgGroup.GET("/:orgId", organizationController.GetOrganizationHandler, anyMember)                                              // Get an organization and its members
+	orgGroup.PUT("/:orgId", organizationController.RenameOrganizationHandler, managers)                                            // Rename an organization
+	orgGroup.DELETE("/:orgId", organizationController.DeleteOrganizationHandler, owner)                                            // Delete an organization without jobs
+	orgGroup.PUT("/:orgId/members/:userId", organizationController.ChangeMemberRoleHandler, owner)                                 // Change a member's role or transfer the ownership
+	orgGroup.DELETE("/:orgId/members/:userId", organizationController.RemoveMemberHandler, anyMember)                              // Remove a member, or leave
+	orgGroup.POST("/:orgId/invitations", organizationController.InviteHandler, managers, verified)                                 // Invite someone by email
+	orgGroup.GET("/:orgId/invitations", organizationController.ListInvitationsHandler, managers)                                   // List pending invitations
+	orgGroup.DELETE("/:orgId/invitations/:invitationId", organizationController.RevokeInvitationHandler, managers)                 // Revoke a pending invitation
+
+	e.POST("/invitations/accept", organizationController.AcceptInvitationHandler, authenticated, verified) // Join with an emailed token
+	e.GET("/me/orgs", organizationController.MyOrganizationsHandler, authenticated)                        // List my organizations
+}
@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"job-portal/controllers"
+	"job-portal/middlewares"
+
+	"github.com/labstack/echo/v4"
+)
+
+func RegisterProfileRoutes(e *echo.Echo, profileController *controllers.ProfileController, config Config) {
+	authenticated := middlewares.JWTMiddleware(config.JWT)
+	verified := middlewares.RequireVerifiedEmail(config.VerifiedEmail)
+
+	meGroup := e.Group("/me")
+
+	meGroup.GET("/profile", profileController.GetProfileHandler, authenticated)              // Get my profile
+	meGroup.PUT("/profile", profileController.UpdateProfileHandler, authenticated, verified) // Replace my profile
+	meGroup.POST("/resume", profileController.UploadResumeHandler, authenticated, verified)  // Upload my resume
+	meGroup.GET("/resume", profileController.DownloadResumeHandler, authenticated)           // Download my resume
+}
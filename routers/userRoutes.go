@@ -2,11 +2,23 @@ package routers
 
 import (
 	"job-portal/controllers"
+	"job-portal/middlewares"
+	"job-portal/policy"
 
 	"github.com/labstack/echo/v4"
 )
 
-func RegisterUserRoutes(e *echo.Echo, userController *controllers.UserController) {
+func RegisterUserRoutes(e *echo.Echo, userController *controllers.UserController, config Config) {
+	authenticated := middlewares.JWTMiddleware(config.JWT)
+
 	e.POST("/register", userController.Register)
 	e.POST("/login", userController.Login)
+	e.POST("/auth/refresh", userController.Refresh)
+	e.GET("/verify-email", userController.VerifyEmail)
+	e.POST("/verify-email/resend", userController.ResendVerification, authenticated)
+	e.POST("/password/forgot", userController.ForgotPassword)
+	e.POST("/password/reset", userController.ResetPassword)
+	e.POST("/logout", userController.Logout, authenticated)
+	e.POST("/logout-all", userController.LogoutAll, authenticated)
+	e.PUT("/users/:id/role", userController.SetRoleHandler, authenticated, middlewares.RequirePermission(policy.UserManage))
 }
@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Locker hands out named leases, so that when several replicas run the
+// scheduler only one of them runs each task
+type Locker interface {
+	// Acquire takes or renews the lease on name for owner until the given time.
+	// It reports false when another owner holds an unexpired lease.
+	Acquire(ctx context.Context, name, owner string, until time.Time) (bool, error)
+}
+
+var (
+	_ Locker = (*MongoLocker)(nil)
+	_ Locker = (*MemoryLocker)(nil)
+)
+
+// MongoLocker keeps one lease document per task name
+type MongoLocker struct {
+	Collection *mongo.Collection
+}
+
+// NewMongoLocker creates a new instance of MongoLocker
+func NewMongoLocker(collection *mongo.Collection) *MongoLocker {
+	return &MongoLocker{Collection: collection}
+}
+
+type lease struct {
+	Name      string    `bson:"_id"`
+	Owner     string    `bson:"owner"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+func (l *MongoLocker) Acquire(ctx context.Context, name, owner string, until time.Time) (bool, error) {
+	// Matches a free or expired lease, or one we already hold. If another owner
+	// holds it, the upsert tries to insert a second document with the same _id
+	// and fails with a duplicate key error, which means the lease is taken.
+	filter := bson.M{
+		"_id": name,
+		"$or": bson.A{
+			bson.M{"owner": owner},
+			bson.M{"expires_at": bson.M{"$lte": time.Now()}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"owner": owner, "expires_at": until}}
+	_, err := l.Collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// MemoryLocker keeps leases in memory. It only coordinates schedulers within
+// one process, which makes it suitable for single-instance setups and tests.
+type MemoryLocker struct {
+	mu     sync.Mutex
+	leases map[string]lease
+}
+
+// NewMemoryLocker creates an empty MemoryLocker
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{leases: map[string]lease{}}
+}
+
+func (l *MemoryLocker) Acquire(ctx context.Context, name, owner string, until time.Time) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current, held := l.leases[name]
+	if held && current.Owner != owner && current.ExpiresAt.After(time.Now()) {
+		return false, nil
+	}
+	l.leases[name] = lease{Name: name, Owner: owner, ExpiresAt: until}
+	return true, nil
+}
@@ -0,0 +1,33 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLocker(t *testing.T) {
+	ctx := context.Background()
+	locker := NewMemoryLocker()
+	now := time.Now()
+
+	acquire := func(name, owner string, until time.Time, want bool) {
+		t.Helper()
+		got, err := locker.Acquire(ctx, name, owner, until)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("Acquire(%q, %q) = %v, want %v", name, owner, got, want)
+		}
+	}
+
+	acquire("digest", "a", now.Add(time.Minute), true)
+	acquire("digest", "b", now.Add(time.Minute), false)  // Contended
+	acquire("digest", "a", now.Add(2*time.Minute), true) // The holder renews
+	acquire("cleanup", "b", now.Add(time.Minute), true)  // Leases are per name
+
+	acquire("expiring", "a", now.Add(-time.Second), true)
+	acquire("expiring", "b", now.Add(time.Minute), true) // An expired lease is free
+	acquire("expiring", "a", now.Add(time.Minute), false)
+}
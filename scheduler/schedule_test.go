@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRejectsInvalidSpecs(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@every 10ms",
+		"@every soon",
+		"@yearly",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) accepted an invalid spec", spec)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	// 2024-01-01 is a Monday
+	tests := []struct {
+		spec  string
+		after time.Time
+		want  time.Time
+	}{
+		{"@every 90s", at("2024-01-01 10:07"), at("2024-01-01 10:07").Add(90 * time.Second)},
+		{"@hourly", at("2024-01-01 10:07"), at("2024-01-01 11:00")},
+		{"@daily", at("2024-01-01 10:00"), at("2024-01-02 00:00")},
+		{"@weekly", at("2024-01-01 10:00"), at("2024-01-07 00:00")},
+		{"0 10 * * *", at("2024-01-01 10:00"), at("2024-01-02 10:00")}, // Strictly after
+		{"0 10 * * *", at("2024-01-01 10:00").Add(-time.Second), at("2024-01-01 10:00")},
+		{"*/15 * * * *", at("2024-01-01 10:07"), at("2024-01-01 10:15")},
+		{"5/15 * * * *", at("2024-01-01 10:07"), at("2024-01-01 10:20")}, // n/step runs from n to the end
+		{"5/15 * * * *", at("2024-01-01 10:50"), at("2024-01-01 11:05")},
+		{"0 9-17/4 * * *", at("2024-01-01 14:00"), at("2024-01-01 17:00")},
+		{"0 0,12 * * *", at("2024-01-01 01:00"), at("2024-01-01 12:00")},
+		{"0 0 * * 0", at("2024-01-01 00:00"), at("2024-01-07 00:00")}, // Sunday as 0
+		{"0 0 * * 7", at("2024-01-01 00:00"), at("2024-01-07 00:00")}, // and as 7
+		{"0 0 * * 5-7", at("2024-01-06 12:00"), at("2024-01-07 00:00")},
+		{"0 0 1 * 1", at("2024-01-01 00:00"), at("2024-01-08 00:00")}, // Day of month or day of week
+		{"0 0 1 * 1", at("2024-01-29 00:00"), at("2024-02-01 00:00")},
+		{"0 0 13 * 5", at("2024-01-01 00:00"), at("2024-01-05 00:00")},
+		{"0 0 13 * *", at("2024-01-01 00:00"), at("2024-01-13 00:00")}, // Only day of month
+		{"0 0 */2 * *", at("2024-01-01 00:00"), at("2024-01-03 00:00")},
+		{"0 0 31 * *", at("2024-02-01 00:00"), at("2024-03-31 00:00")},
+		{"0 0 29 2 *", at("2024-03-01 00:00"), at("2028-02-29 00:00")},
+		{"0 0 30 2 *", at("2024-01-01 00:00"), time.Time{}}, // Never happens
+		{"0 0 31 4,6 *", at("2024-01-01 00:00"), time.Time{}},
+	}
+
+	for _, tt := range tests {
+		schedule, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("Parse(%q) = %v", tt.spec, err)
+			continue
+		}
+		if got := schedule.Next(tt.after); !got.Equal(tt.want) {
+			t.Errorf("%q after %v: Next = %v, want %v", tt.spec, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestNextIsInUTC(t *testing.T) {
+	schedule, err := Parse("0 0 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dhaka := time.FixedZone("UTC+6", 6*60*60)
+	after := time.Date(2024, 1, 1, 5, 0, 0, 0, dhaka) // 2023-12-31 23:00 UTC
+	if got, want := schedule.Next(after), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds a task run when the task does not set its own timeout
+const DefaultTimeout = 5 * time.Minute
+
+// leaseMargin ends a lease slightly before the next run, so that whichever
+// replica reaches the next run first can take it over
+const leaseMargin = time.Second
+
+// Task is a unit of background work. Runs may occasionally overlap across
+// replicas, e.g. with clock skew or a run longer than its interval, so Run
+// should be idempotent.
+type Task struct {
+	Name     string
+	Schedule Schedule
+	Timeout  time.Duration // Defaults to DefaultTimeout
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs registered tasks on their schedules. Each run first takes a
+// lease from the Locker that lasts until the next run, so only one replica runs
+// each occurrence of a task.
+type Scheduler struct {
+	Locker Locker
+	Owner  string // Identifies this replica in leases
+
+	mu      sync.Mutex
+	tasks   []*Task
+	names   map[string]bool
+	started bool
+
+	stop       chan struct{}      // Closed by Stop: no new runs start
+	runCtx     context.Context    // Passed to runs, cancelled if Stop runs out of time
+	cancelRuns context.CancelFunc // Cancels runCtx
+	wg         sync.WaitGroup
+}
+
+// New creates a scheduler that coordinates with other replicas through locker
+func New(locker Locker) *Scheduler {
+	runCtx, cancelRuns := context.WithCancel(context.Background())
+	return &Scheduler{
+		Locker:     locker,
+		Owner:      replicaID(),
+		names:      map[string]bool{},
+		stop:       make(chan struct{}),
+		runCtx:     runCtx,
+		cancelRuns: cancelRuns,
+	}
+}
+
+// Register adds a task. Tasks must be registered before Start, under unique names.
+func (s *Scheduler) Register(task Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case s.started:
+		return fmt.Errorf("cannot register task %q after the scheduler has started", task.Name)
+	case task.Name == "" || task.Schedule == nil || task.Run == nil:
+		return fmt.Errorf("task %q needs a name, a schedule and a run function", task.Name)
+	case s.names[task.Name]:
+		return fmt.Errorf("task %q is already registered", task.Name)
+	}
+	if task.Timeout <= 0 {
+		task.Timeout = DefaultTimeout
+	}
+
+	s.names[task.Name] = true
+	s.tasks = append(s.tasks, &task)
+	return nil
+}
+
+// Start runs every registered task on its schedule until Stop is called
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+	for _, task := range s.tasks {
+		s.wg.Add(1)
+		go s.loop(task)
+	}
+	log.Printf("Scheduler started with %d task(s) as %s", len(s.tasks), s.Owner)
+}
+
+// Stop stops scheduling new runs and waits for the running ones to finish. If
+// ctx ends first, the running tasks are cancelled and ctx's error is returned.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancelRuns()
+		return nil
+	case <-ctx.Done():
+		s.cancelRuns()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// loop waits for each scheduled time of the task and runs it
+func (s *Scheduler) loop(task *Task) {
+	defer s.wg.Done()
+
+	for {
+		at := task.Schedule.Next(time.Now())
+		if at.IsZero() {
+			log.Printf("Scheduler: task %s has no upcoming run, stopping it", task.Name)
+			return
+		}
+
+		timer := time.NewTimer(time.Until(at))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.run(task, at)
+	}
+}
+
+// run takes the lease for the occurrence at the given time and, if this replica got it, runs the task
+func (s *Scheduler) run(task *Task, at time.Time) {
+	until := task.Schedule.Next(at).Add(-leaseMargin)
+	if minimum := time.Now().Add(task.Timeout); until.Before(minimum) {
+		until = minimum // Hold the lease for at least as long as the run may take
+	}
+
+	lockCtx, cancel := context.WithTimeout(s.runCtx, 10*time.Second)
+	acquired, err := s.Locker.Acquire(lockCtx, task.Name, s.Owner, until)
+	cancel()
+	if err != nil {
+		log.Printf("Scheduler: failed to acquire lease for task %s: %v", task.Name, err)
+		return
+	}
+	if !acquired {
+		return // Another replica runs this occurrence
+	}
+
+	ctx, cancel := context.WithTimeout(s.runCtx, task.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Scheduler: task %s panicked: %v", task.Name, r)
+		}
+	}()
+	if err := task.Run(ctx); err != nil {
+		log.Printf("Scheduler: task %s failed after %s: %v", task.Name, time.Since(start).Round(time.Millisecond), err)
+		return
+	}
+	log.Printf("Scheduler: task %s finished in %s", task.Name, time.Since(start).Round(time.Millisecond))
+}
+
+// replicaID returns an ID that is unique to this process, prefixed with the host name for readability
+func replicaID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return host
+	}
+	return host + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"job-portal/models"
+	"job-portal/policy"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OrgMembers looks up the role of a user in an organization; "" means the user is not a member
+type OrgMembers interface {
+	MemberRole(orgID, userID string) (string, error)
+}
+
+// canManageCompany reports whether the user may edit the company and post jobs
+// for it: with company:update:any any company, with company:update:own the ones
+// they created, and as a member of its organization any company of that organization
+func canManageCompany(orgs OrgMembers, company *models.Company, userID, role string) (bool, error) {
+	return canActOn(orgs, company.CreatedBy, company.OrgID, userID, role, policy.CompanyUpdate)
+}
+
+// canActOn applies policy.CanActOn to a resource the given user created and that
+// belongs to orgID. The membership lookup is skipped when the role may perform
+// action on any resource.
+func canActOn(orgs OrgMembers, createdBy, orgID primitive.ObjectID, userID, role, action string) (bool, error) {
+	if policy.Has(role, policy.Permission(action+":any")) {
+		return true, nil
+	}
+	orgRole, err := memberRole(orgs, orgID, userID)
+	if err != nil {
+		return false, err
+	}
+	created := !createdBy.IsZero() && createdBy.Hex() == userID
+	return policy.CanActOn(role, orgRole, action, created), nil
+}
+
+// memberRole returns the user's role in the organization, "" if they do not
+// belong to it or no organization is given
+func memberRole(orgs OrgMembers, orgID primitive.ObjectID, userID string) (string, error) {
+	if orgID.IsZero() || orgs == nil {
+		return "", nil
+	}
+	return orgs.MemberRole(orgID.Hex(), userID)
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"job-portal/apperrors"
+	"job-portal/models"
+	"job-portal/policy"
+	"job-portal/repositories"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrJobNotOpen           = apperrors.Conflict("this job is not accepting applications")
+	ErrAlreadyApplied       = apperrors.Conflict("you have already applied to this job")
+	ErrApplicationNotFound  = apperrors.NotFound("application not found")
+	ErrInvalidStatusChange  = apperrors.Validation("invalid application status change", nil)
+	ErrNotApplicationOwner  = apperrors.Forbidden("application belongs to another user")
+	ErrInvalidApplicationID = apperrors.InvalidID("invalid application ID format")
+	ErrCannotReview         = apperrors.Forbidden("your role cannot review applications")
+)
+
+type ApplicationService struct {
+	Repo       repositories.ApplicationRepository
+	JobService *JobService
+	Profiles   *ProfileService // Shows reviewers the applicant's profile and resume
+}
+
+// NewApplicationService creates a new instance of ApplicationService
+func NewApplicationService(repo repositories.ApplicationRepository, jobService *JobService, profiles *ProfileService) *ApplicationService {
+	return &ApplicationService{Repo: repo, JobService: jobService, Profiles: profiles}
+}
+
+// ApplicationDetails is an application together with the applicant's profile
+type ApplicationDetails struct {
+	Application *models.Application `json:"application"`
+	Profile     *models.Profile     `json:"profile"`
+}
+
+// Apply submits an application from the given user to the given job
+func (s *ApplicationService) Apply(jobID, userID string, application *models.Application) error {
+	job, err := s.JobService.GetJob(jobID)
+	if err != nil {
+		return err
+	}
+	if !job.IsOpen(time.Now()) {
+		return ErrJobNotOpen
+	}
+
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return apperrors.InvalidID("invalid user ID format")
+	}
+
+	now := time.Now()
+	application.ID = primitive.NewObjectID()
+	application.JobID = job.ID
+	application.UserID = userObjID
+	application.Status = models.StatusSubmitted
+	application.History = []models.StatusChange{{Status: models.StatusSubmitted, ChangedBy: userID, ChangedAt: now}}
+	application.CreatedAt = now
+	application.UpdatedAt = now
+
+	// A candidate may only apply once to the same job
+	err = s.Repo.Create(context.TODO(), application)
+	if errors.Is(err, repositories.ErrDuplicate) {
+		return ErrAlreadyApplied
+	}
+	return err
+}
+
+// ListByJob retrieves every application submitted to a job, newest first, for
+// a reviewer who may manage the job
+func (s *ApplicationService) ListByJob(jobID, userID, role string) ([]models.Application, error) {
+	job, err := s.reviewableJob(jobID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	return s.Repo.ListByJob(context.TODO(), job.ID)
+}
+
+// ListByUser retrieves every application submitted by a user, newest first
+func (s *ApplicationService) ListByUser(userID string) ([]models.Application, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, apperrors.InvalidID("invalid user ID format")
+	}
+	return s.Repo.ListByUser(context.TODO(), userObjID)
+}
+
+// GetForReview retrieves an application of the given job with the applicant's
+// profile, for a reviewer who may manage the job
+func (s *ApplicationService) GetForReview(jobID, applicationID, userID, role string) (*ApplicationDetails, error) {
+	application, err := s.reviewableApplication(jobID, applicationID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := s.Profiles.GetProfile(application.UserID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	return &ApplicationDetails{Application: application, Profile: profile}, nil
+}
+
+// OpenApplicantResume opens the resume of the applicant behind an application
+// of the given job, for a reviewer who may manage the job. The caller closes the content.
+func (s *ApplicationService) OpenApplicantResume(jobID, applicationID, userID, role string) (*models.Resume, io.ReadCloser, error) {
+	application, err := s.reviewableApplication(jobID, applicationID, userID, role)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.Profiles.OpenResume(application.UserID.Hex())
+}
+
+// UpdateStatus moves an application of the given job along the status pipeline,
+// on behalf of a reviewer who may manage the job
+func (s *ApplicationService) UpdateStatus(jobID, applicationID, status, changedBy, role string) (*models.Application, error) {
+	application, err := s.reviewableApplication(jobID, applicationID, changedBy, role)
+	if err != nil {
+		return nil, err
+	}
+	if status == models.StatusWithdrawn {
+		// Only the candidate can withdraw an application
+		return nil, ErrInvalidStatusChange
+	}
+	return s.transition(application, status, changedBy)
+}
+
+// Withdraw lets a candidate pull back one of their own applications
+func (s *ApplicationService) Withdraw(applicationID, userID string) (*models.Application, error) {
+	application, err := s.get(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.UserID.Hex() != userID {
+		return nil, ErrNotApplicationOwner
+	}
+	return s.transition(application, models.StatusWithdrawn, userID)
+}
+
+// transition applies a status change if the pipeline allows it
+func (s *ApplicationService) transition(application *models.Application, status, changedBy string) (*models.Application, error) {
+	if !models.CanTransitionApplication(application.Status, status) {
+		return nil, ErrInvalidStatusChange
+	}
+
+	now := time.Now()
+	change := models.StatusChange{Status: status, ChangedBy: changedBy, ChangedAt: now}
+
+	// A concurrent reviewer who moved the application first makes this step invalid
+	err := s.Repo.UpdateStatus(context.TODO(), application.ID, application.Status, change)
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		return nil, ErrInvalidStatusChange
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	application.Status = status
+	application.UpdatedAt = now
+	application.History = append(application.History, change)
+	return application, nil
+}
+
+// reviewableJob retrieves a job whose applications the user may review: the role,
+// or membership of the job's organization, must grant application:review, and
+// the user must be allowed to update the job
+func (s *ApplicationService) reviewableJob(jobID, userID, role string) (*models.Job, error) {
+	job, err := s.JobService.GetJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	orgRole, err := memberRole(s.JobService.Orgs, job.OrgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.HasInOrg(role, orgRole, policy.ApplicationReview) {
+		return nil, ErrCannotReview
+	}
+	if err := s.JobService.checkCanModify(job, userID, role); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// reviewableApplication retrieves an application of a job whose applications the user may review
+func (s *ApplicationService) reviewableApplication(jobID, applicationID, userID, role string) (*models.Application, error) {
+	job, err := s.reviewableJob(jobID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	application, err := s.get(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.JobID != job.ID {
+		return nil, ErrApplicationNotFound
+	}
+	return application, nil
+}
+
+func (s *ApplicationService) get(id string) (*models.Application, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrInvalidApplicationID
+	}
+
+	application, err := s.Repo.FindByID(context.TODO(), objID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrApplicationNotFound
+	}
+	return application, err
+}
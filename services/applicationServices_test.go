@@ -0,0 +1,105 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"job-portal/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestApplicationPipeline(t *testing.T) {
+	f := newFixture(t)
+	s := f.applications
+	recruiterID, candidate, other := primitive.NewObjectID(), primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	recruiter := recruiterID.Hex()
+	job := f.openJob(t, recruiterID)
+
+	application := &models.Application{CoverLetter: "Hello"}
+	if err := s.Apply(job.ID.Hex(), candidate, application); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Apply(job.ID.Hex(), candidate, &models.Application{}); !errors.Is(err, ErrAlreadyApplied) {
+		t.Errorf("applying twice gave %v, want ErrAlreadyApplied", err)
+	}
+
+	if _, err := s.UpdateStatus(job.ID.Hex(), application.ID.Hex(), models.StatusInterview, recruiter, models.RoleRecruiter); !errors.Is(err, ErrInvalidStatusChange) {
+		t.Errorf("skipping screening gave %v, want ErrInvalidStatusChange", err)
+	}
+	if _, err := s.UpdateStatus(job.ID.Hex(), application.ID.Hex(), models.StatusScreening, candidate, models.RoleUser); !errors.Is(err, ErrCannotReview) {
+		t.Errorf("a candidate reviewing gave %v, want ErrCannotReview", err)
+	}
+	if _, err := s.UpdateStatus(job.ID.Hex(), application.ID.Hex(), models.StatusScreening, recruiter, models.RoleRecruiter); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Withdraw(application.ID.Hex(), other); !errors.Is(err, ErrNotApplicationOwner) {
+		t.Errorf("withdrawing another user's application gave %v, want ErrNotApplicationOwner", err)
+	}
+	if _, err := s.Withdraw(application.ID.Hex(), candidate); err != nil {
+		t.Fatal(err)
+	}
+
+	mine, err := s.ListByUser(candidate)
+	if err != nil || len(mine) != 1 {
+		t.Fatalf("the candidate's applications = %+v, %v", mine, err)
+	}
+	var statuses []string
+	for _, change := range mine[0].History {
+		statuses = append(statuses, change.Status)
+	}
+	want := []string{models.StatusSubmitted, models.StatusScreening, models.StatusWithdrawn}
+	if mine[0].Status != models.StatusWithdrawn || !equalStrings(statuses, want) {
+		t.Errorf("status = %q, history = %v; want withdrawn after %v", mine[0].Status, statuses, want)
+	}
+
+	reviewed, err := s.ListByJob(job.ID.Hex(), recruiter, models.RoleRecruiter)
+	if err != nil || len(reviewed) != 1 || reviewed[0].ID != application.ID {
+		t.Errorf("the job's applications = %+v, %v", reviewed, err)
+	}
+}
+
+func TestReviewersSeeTheApplicantsResume(t *testing.T) {
+	f := newFixture(t)
+	s, profiles := f.applications, f.profiles
+	recruiter, candidate, other := primitive.NewObjectID(), primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+	job := f.openJob(t, recruiter)
+	application := &models.Application{}
+	if err := s.Apply(job.ID.Hex(), candidate, application); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := s.OpenApplicantResume(job.ID.Hex(), application.ID.Hex(), recruiter.Hex(), models.RoleRecruiter); !errors.Is(err, ErrResumeNotFound) {
+		t.Errorf("opening a missing resume gave %v, want ErrResumeNotFound", err)
+	}
+	if _, err := profiles.UploadResume(candidate, "cv.pdf", bytes.NewReader(pdf(1000))); err != nil {
+		t.Fatal(err)
+	}
+
+	details, err := s.GetForReview(job.ID.Hex(), application.ID.Hex(), recruiter.Hex(), models.RoleRecruiter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.Profile.Resume == nil || details.Profile.Resume.FileName != "cv.pdf" {
+		t.Errorf("the applicant's profile = %+v", details.Profile)
+	}
+	resume, content, err := s.OpenApplicantResume(job.ID.Hex(), application.ID.Hex(), recruiter.Hex(), models.RoleRecruiter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(content)
+	content.Close()
+	if int64(len(data)) != resume.Size || !bytes.HasPrefix(data, []byte("%PDF")) {
+		t.Errorf("read %d bytes of a %d byte resume", len(data), resume.Size)
+	}
+
+	// Only reviewers of the job get to see it
+	if _, _, err := s.OpenApplicantResume(job.ID.Hex(), application.ID.Hex(), other, models.RoleRecruiter); !errors.Is(err, ErrNotJobOwner) {
+		t.Errorf("another recruiter gave %v, want ErrNotJobOwner", err)
+	}
+	if _, err := s.GetForReview(job.ID.Hex(), application.ID.Hex(), candidate, models.RoleUser); !errors.Is(err, ErrCannotReview) {
+		t.Errorf("the candidate gave %v, want ErrCannotReview", err)
+	}
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"job-portal/apperrors"
+	"job-portal/models"
+	"job-portal/repositories"
+	"job-portal/utils"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrCompanyNotFound   = apperrors.NotFound("company not found")
+	ErrNotCompanyManager = apperrors.Forbidden("only the company owner, its organization or an admin can change this company")
+	ErrCompanyHasJobs    = apperrors.Conflict("the company still has jobs, delete or move them first")
+	ErrCompanySlugTaken  = apperrors.Conflict("too many companies share this name")
+)
+
+// maxSlugAttempts bounds how many numbered variants of a taken slug are tried
+const maxSlugAttempts = 20
+
+// OpenJobLister lists the jobs the public can see
+type OpenJobLister interface {
+	ListOpenJobs(filter repositories.JobFilter, opts ListOptions) ([]models.Job, map[string]interface{}, error)
+}
+
+type CompanyService struct {
+	Repo     repositories.CompanyRepository
+	Jobs     repositories.JobRepository // Keeps the company's name and logo on its jobs in sync
+	Orgs     OrgMembers                 // Lets teammates manage the companies of their organization
+	Listings OpenJobLister
+}
+
+// NewCompanyService creates a new instance of CompanyService
+func NewCompanyService(repo repositories.CompanyRepository, jobs repositories.JobRepository, orgs OrgMembers, listings OpenJobLister) *CompanyService {
+	return &CompanyService{Repo: repo, Jobs: jobs, Orgs: orgs, Listings: listings}
+}
+
+// CreateCompany adds a new, unverified company owned by the given user and, if
+// orgID is set, shared with the user's active organization. Its slug comes from
+// the name, numbered when another company already has it, or else from its ID.
+func (s *CompanyService) CreateCompany(company *models.Company, userID, orgID string) error {
+	ownerID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return apperrors.InvalidID("invalid user ID format")
+	}
+	company.OrgID = primitive.NilObjectID
+	if orgID != "" {
+		if company.OrgID, err = primitive.ObjectIDFromHex(orgID); err != nil {
+			return apperrors.InvalidID("invalid organization ID format")
+		}
+	}
+
+	company.ID = primitive.NewObjectID()
+	base := utils.Slugify(company.Name)
+	if base == "" {
+		base = "company-" + company.ID.Hex() // The name has no letters or digits, e.g. "???"
+	}
+
+	company.Verified = false
+	company.CreatedBy = ownerID
+	company.CreatedAt = time.Now()
+	company.UpdatedAt = company.CreatedAt
+
+	for attempt := 1; attempt <= maxSlugAttempts; attempt++ {
+		company.Slug = base
+		if attempt > 1 {
+			company.Slug = fmt.Sprintf("%s-%d", base, attempt)
+		}
+		err := s.Repo.Create(context.TODO(), company)
+		if !errors.Is(err, repositories.ErrDuplicate) {
+			return err
+		}
+	}
+	return ErrCompanySlugTaken
+}
+
+// GetCompany retrieves a company by its slug
+func (s *CompanyService) GetCompany(slug string) (*models.Company, error) {
+	company, err := s.Repo.FindBySlug(context.TODO(), slug)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrCompanyNotFound
+	}
+	return company, err
+}
+
+// ListCompanies retrieves companies ordered by name, one page at a time
+func (s *CompanyService) ListCompanies(page, pageSize int) ([]models.Company, map[string]interface{}, error) {
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	companies, totalItems, err := s.Repo.List(context.TODO(), int64((page-1)*pageSize), int64(pageSize))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := map[string]interface{}{
+		"totalItems":  totalItems,
+		"totalPages":  int(math.Ceil(float64(totalItems) / float64(pageSize))),
+		"currentPage": page,
+		"pageSize":    pageSize,
+	}
+	return companies, pagination, nil
+}
+
+// UpdateCompany replaces the editable details of a company. The slug stays the
+// same so links keep working, and the company's jobs get the new name and logo.
+func (s *CompanyService) UpdateCompany(slug, userID, role string, update *models.Company) (*models.Company, error) {
+	company, err := s.GetCompany(slug)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCanManage(company, userID, role); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.Repo.Update(context.TODO(), company.ID, map[string]interface{}{
+		"name":        update.Name,
+		"logo":        update.Logo,
+		"description": update.Description,
+		"website":     update.Website,
+		"updated_at":  time.Now(),
+	})
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrCompanyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.Jobs.SyncCompany(context.TODO(), updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// SetVerified marks a company as confirmed to be real, or withdraws that mark
+func (s *CompanyService) SetVerified(slug string, verified bool) (*models.Company, error) {
+	company, err := s.GetCompany(slug)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.Repo.Update(context.TODO(), company.ID, map[string]interface{}{"verified": verified, "updated_at": time.Now()})
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrCompanyNotFound
+	}
+	return updated, err
+}
+
+// DeleteCompany removes a company that no longer has any jobs, in any status
+func (s *CompanyService) DeleteCompany(slug, userID, role string) error {
+	company, err := s.GetCompany(slug)
+	if err != nil {
+		return err
+	}
+	if err := s.checkCanManage(company, userID, role); err != nil {
+		return err
+	}
+
+	jobs, err := s.Jobs.Count(context.TODO(), repositories.JobFilter{CompanyID: company.ID}, 1)
+	if err != nil {
+		return err
+	}
+	if jobs > 0 {
+		return ErrCompanyHasJobs
+	}
+
+	err = s.Repo.Delete(context.TODO(), company.ID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrCompanyNotFound
+	}
+	return err
+}
+
+// CompanyJobs lists the open jobs of a company
+func (s *CompanyService) CompanyJobs(company *models.Company, opts ListOptions) ([]models.Job, map[string]interface{}, error) {
+	return s.Listings.ListOpenJobs(repositories.JobFilter{CompanyID: company.ID}, opts)
+}
+
+// checkCanManage returns ErrNotCompanyManager if the user may not edit the company
+func (s *CompanyService) checkCanManage(company *models.Company, userID, role string) error {
+	allowed, err := canManageCompany(s.Orgs, company, userID, role)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrNotCompanyManager
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"job-portal/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUpdateCompanyMovesItsJobsToANewVersion(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	s, jobs := f.companies, f.jobs
+	owner := primitive.NewObjectID()
+
+	company := &models.Company{Name: "Café Ünïcode", Logo: "https://example.com/old.png"}
+	if err := s.CreateCompany(company, owner.Hex(), ""); err != nil {
+		t.Fatal(err)
+	}
+	if company.Slug != "cafe-unicode" {
+		t.Errorf("slug = %q, want cafe-unicode", company.Slug)
+	}
+	job := &models.Job{Title: "Barista", Description: "Makes coffee", Location: "Paris", CompanyID: company.ID}
+	if err := jobs.CreateJob(job, owner.Hex(), models.RoleRecruiter, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.UpdateCompany(company.Slug, owner.Hex(), models.RoleRecruiter, &models.Company{Name: "Café Deux", Logo: "https://example.com/new.png"}); err != nil {
+		t.Fatal(err)
+	}
+	synced, err := jobs.Repo.FindByID(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if synced.CompanyName != "Café Deux" || synced.CompanyLogo != "https://example.com/new.png" {
+		t.Errorf("the job shows %q with %q", synced.CompanyName, synced.CompanyLogo)
+	}
+	if synced.Version != job.Version+1 {
+		t.Errorf("the job is at version %d after the sync, want %d", synced.Version, job.Version+1)
+	}
+
+	// A company without letters or digits in its name still gets a slug
+	symbols := &models.Company{Name: "???"}
+	if err := s.CreateCompany(symbols, owner.Hex(), ""); err != nil {
+		t.Fatal(err)
+	}
+	if symbols.Slug != "company-"+symbols.ID.Hex() {
+		t.Errorf("slug = %q, want one derived from the ID", symbols.Slug)
+	}
+}
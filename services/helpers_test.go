@@ -0,0 +1,142 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"job-portal/currency"
+	"job-portal/geo"
+	"job-portal/mailer"
+	"job-portal/models"
+	"job-portal/repositories"
+	"job-portal/storage"
+	"job-portal/utils"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fixture wires the services together on the in-memory repositories, the way
+// main wires them on MongoDB
+type fixture struct {
+	jobs         *JobService
+	companies    *CompanyService
+	orgs         *OrganizationService
+	profiles     *ProfileService
+	applications *ApplicationService
+	mail         *outbox
+	uploads      string // Directory of the profiles' blob store
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	jobRepo := repositories.NewMemoryJobRepository()
+	companyRepo := repositories.NewMemoryCompanyRepository()
+	f := &fixture{mail: &outbox{}, uploads: t.TempDir()}
+	f.orgs = NewOrganizationService(repositories.NewMemoryOrganizationRepository(), jobRepo, f.mail, "https://portal.test")
+	f.jobs = NewJobService(jobRepo, utils.NewSigner([]byte("test-cursor-key")), currency.Default(), geo.DefaultGazetteer(), companyRepo, f.orgs)
+	f.companies = NewCompanyService(companyRepo, jobRepo, f.orgs, f.jobs)
+	f.profiles = NewProfileService(repositories.NewMemoryProfileRepository(), storage.NewLocalStore(f.uploads))
+	f.applications = NewApplicationService(repositories.NewMemoryApplicationRepository(), f.jobs, f.profiles)
+	return f
+}
+
+// company stores a company created by owner, in orgID unless it is zero
+func (f *fixture) company(t *testing.T, owner, orgID primitive.ObjectID) *models.Company {
+	t.Helper()
+	company := &models.Company{ID: primitive.NewObjectID(), Name: "Acme", CreatedBy: owner, OrgID: orgID}
+	if err := f.jobs.Companies.Create(context.Background(), company); err != nil {
+		t.Fatal(err)
+	}
+	return company
+}
+
+// openJob stores a job created by owner that has been taking applications for an hour
+func (f *fixture) openJob(t *testing.T, owner primitive.ObjectID) *models.Job {
+	t.Helper()
+	job := &models.Job{ID: primitive.NewObjectID(), Title: "Engineer", Status: models.JobStatusPublished,
+		PostedAt: time.Now().Add(-time.Hour), Version: 1, CreatedBy: owner}
+	if err := f.jobs.Repo.Create(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+// noValidation stands in for the request validator in UpdateJob
+func noValidation(interface{}) error { return nil }
+
+// outbox records the messages sent through it
+type outbox struct {
+	messages []mailer.Message
+}
+
+func (o *outbox) Send(msg mailer.Message) error {
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+// invitationToken extracts the token from the link of the last message sent
+func (o *outbox) invitationToken(t *testing.T) string {
+	t.Helper()
+	if len(o.messages) == 0 {
+		t.Fatal("no message was sent")
+	}
+	body := o.messages[len(o.messages)-1].Body
+	i := strings.Index(body, "token=")
+	if i < 0 {
+		t.Fatalf("no token in %q", body)
+	}
+	token, err := url.QueryUnescape(strings.Fields(body[i+len("token="):])[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// pdf returns size bytes that sniff as a PDF
+func pdf(size int) []byte {
+	content := bytes.Repeat([]byte("a"), size)
+	copy(content, "%PDF-1.7\n")
+	return content
+}
+
+func titles(jobs []models.Job) []string {
+	names := make([]string, len(jobs))
+	for i, job := range jobs {
+		names[i] = job.Title
+	}
+	return names
+}
+
+func ids(jobs []models.Job) []string {
+	hexes := make([]string, len(jobs))
+	for i, job := range jobs {
+		hexes[i] = job.ID.Hex()
+	}
+	return hexes
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]string(nil), a...), append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return equalStrings(a, b)
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"fmt"
+	"job-portal/apperrors"
+	"job-portal/currency"
+	"job-portal/geo"
+	"job-portal/models"
+	"job-portal/repositories"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JobFilterParams holds the raw filter query parameters of a job listing.
+// List parameters are comma separated.
+type JobFilterParams struct {
+	DatePosted   string // anytime, last_24_hours, last_7_days or last_30_days
+	PostedAfter  string // RFC 3339 time, or a date meaning its start
+	PostedBefore string // RFC 3339 time, or a date meaning its end
+	JobType      string // List of full-time, part-time, contract
+	Experience   string // List of entry, mid, senior
+	Education    string // List of bachelor, master, phd
+	WorkLocation string // List of on-site, remote, hybrid
+	Skills       string // List of skills
+	SkillsMatch  string // any (the default) or all
+	Location     string
+	Company      string
+	SalaryRange  string // min-max, or min+ for open-ended; amounts may use a k suffix, e.g. 0-2.5k
+	Currency     string // ISO 4217 code the salary range is in, the base currency by default
+	Period       string // hour, month or year (the default) the salary range is per
+	Search       string
+	Near         string // lat,lng to search around
+	RadiusKm     string // Radius around Near, DefaultRadiusKm by default
+	Remote       string // include (the default) or exclude remote jobs outside the radius
+}
+
+// DefaultRadiusKm is the radius of a near search that does not set one
+const DefaultRadiusKm = 25
+
+// maxRadiusKm is half the Earth's circumference, beyond which a radius covers everything
+const maxRadiusKm = 20000
+
+// datePostedWindows maps each datePosted keyword to how far back it reaches; anytime has no limit
+var datePostedWindows = map[string]time.Duration{
+	"anytime":       0,
+	"last_24_hours": 24 * time.Hour,
+	"last_7_days":   7 * 24 * time.Hour,
+	"last_30_days":  30 * 24 * time.Hour,
+}
+
+var datePostedKeywords = []string{"anytime", "last_24_hours", "last_7_days", "last_30_days"}
+
+// ParseJobFilter turns the listing query parameters into a JobFilter, converting
+// the salary range to annual amounts in the base currency of rates. Every
+// malformed parameter is reported in one 400 error, with the valid values.
+func ParseJobFilter(params JobFilterParams, rates *currency.Rates) (repositories.JobFilter, error) {
+	problems := map[string]string{}
+	filter := repositories.JobFilter{
+		Types:         parseEnumList(params.JobType, "jobType", models.JobTypes, problems),
+		Experience:    parseEnumList(params.Experience, "experience", models.ExperienceLevels, problems),
+		Education:     parseEnumList(params.Education, "education", models.EducationLevels, problems),
+		WorkLocations: parseEnumList(params.WorkLocation, "workLocation", models.WorkLocations, problems),
+		Skills:        splitParam(params.Skills),
+		Location:      strings.TrimSpace(params.Location),
+		Company:       strings.TrimSpace(params.Company),
+		Search:        params.Search,
+	}
+
+	switch params.SkillsMatch {
+	case "", "any":
+	case "all":
+		filter.AllSkills = true
+	default:
+		problems["skillsMatch"] = "must be one of: any, all"
+	}
+
+	// Date range: a datePosted keyword and postedAfter may both be given, the later bound wins
+	if params.DatePosted != "" {
+		window, ok := datePostedWindows[params.DatePosted]
+		if !ok {
+			problems["datePosted"] = "must be one of: " + strings.Join(datePostedKeywords, ", ")
+		} else if window > 0 {
+			filter.PostedAfter = time.Now().Add(-window)
+		}
+	}
+	if params.PostedAfter != "" {
+		after, _, err := parseDateParam(params.PostedAfter)
+		if err != nil {
+			problems["postedAfter"] = err.Error()
+		} else if after.After(filter.PostedAfter) {
+			filter.PostedAfter = after
+		}
+	}
+	if params.PostedBefore != "" {
+		before, dateOnly, err := parseDateParam(params.PostedBefore)
+		if err != nil {
+			problems["postedBefore"] = err.Error()
+		} else {
+			if dateOnly {
+				before = before.AddDate(0, 0, 1) // The whole day is included
+			}
+			filter.PostedBefore = before
+		}
+	}
+	if !filter.PostedAfter.IsZero() && !filter.PostedBefore.IsZero() && !filter.PostedBefore.After(filter.PostedAfter) {
+		problems["postedBefore"] = "must be later than postedAfter"
+	}
+
+	if params.SalaryRange != "" {
+		if err := parseSalaryRange(params.SalaryRange, &filter); err != nil {
+			problems["salaryRange"] = err.Error()
+		}
+	}
+	code := strings.ToUpper(params.Currency)
+	if code == "" {
+		code = rates.Base
+	}
+	rate, ok := rates.Rate(code)
+	if !ok {
+		problems["currency"] = "must be one of: " + strings.Join(rates.Currencies(), ", ")
+	}
+	period := params.Period
+	if period == "" {
+		period = models.PeriodYear
+	}
+	perYear, ok := models.PeriodsPerYear[period]
+	if !ok {
+		problems["period"] = "must be one of: " + strings.Join(models.SalaryPeriods, ", ")
+	}
+	for _, bound := range []*float64{filter.MinSalary, filter.MaxSalary} {
+		if bound != nil {
+			*bound = *bound * rate * perYear // Same order as normalizeSalary, so equal amounts stay equal
+		}
+	}
+
+	if params.Near != "" {
+		near, err := geo.ParsePoint(params.Near)
+		if err != nil {
+			problems["near"] = err.Error()
+		} else {
+			filter.Near = &near
+		}
+		filter.RadiusKm = DefaultRadiusKm
+		if params.RadiusKm != "" {
+			radius, err := strconv.ParseFloat(params.RadiusKm, 64)
+			if err != nil || math.IsNaN(radius) || radius <= 0 || radius > maxRadiusKm {
+				problems["radiusKm"] = fmt.Sprintf("must be a number of kilometres above 0 and up to %d", maxRadiusKm)
+			} else {
+				filter.RadiusKm = radius
+			}
+		}
+	} else if params.RadiusKm != "" {
+		problems["radiusKm"] = "requires near"
+	}
+	switch params.Remote {
+	case "", "include":
+	case "exclude":
+		filter.ExcludeRemote = true
+	default:
+		problems["remote"] = "must be one of: include, exclude"
+	}
+
+	if len(problems) > 0 {
+		return filter, apperrors.InvalidParams("invalid filter parameters", problems)
+	}
+	return filter, nil
+}
+
+// parseEnumList splits a list parameter and checks every item is a valid value
+func parseEnumList(param, name string, valid []string, problems map[string]string) []string {
+	values := splitParam(strings.ToLower(param))
+	for _, value := range values {
+		known := false
+		for _, v := range valid {
+			known = known || value == v
+		}
+		if !known {
+			problems[name] = fmt.Sprintf("unknown value %q, valid values are: %s", value, strings.Join(valid, ", "))
+			return nil
+		}
+	}
+	return values
+}
+
+// splitParam splits a comma separated parameter, dropping empty items
+func splitParam(param string) []string {
+	var items []string
+	for _, item := range strings.Split(param, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseDateParam accepts an RFC 3339 time or a YYYY-MM-DD date in UTC, reporting which it was
+func parseDateParam(param string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, param); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.Parse(time.DateOnly, param); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("must be a date (2006-01-02) or an RFC 3339 time (2006-01-02T15:04:05Z), got %q", param)
+}
+
+// parseSalaryRange reads "min-max" or the open-ended "min+", as used by the salary_bucket facet
+func parseSalaryRange(salaryRange string, filter *repositories.JobFilter) error {
+	if strings.HasSuffix(salaryRange, "+") {
+		minSalary, err := parseAmount(strings.TrimSuffix(salaryRange, "+"))
+		if err != nil {
+			return fmt.Errorf("invalid min salary in %q", salaryRange)
+		}
+		filter.MinSalary = &minSalary
+		return nil
+	}
+
+	minPart, maxPart, ok := strings.Cut(salaryRange, "-")
+	if !ok {
+		return fmt.Errorf("must look like 50000-100000, 0-2.5k or 150000+, got %q", salaryRange)
+	}
+	minSalary, err := parseAmount(minPart)
+	if err != nil {
+		return fmt.Errorf("invalid min salary %q", minPart)
+	}
+	maxSalary, err := parseAmount(maxPart)
+	if err != nil {
+		return fmt.Errorf("invalid max salary %q", maxPart)
+	}
+	if maxSalary < minSalary {
+		return fmt.Errorf("max salary %q is below min salary %q", maxPart, minPart)
+	}
+	filter.MinSalary = &minSalary
+	filter.MaxSalary = &maxSalary
+	return nil
+}
+
+// parseAmount reads a non-negative number with an optional k suffix for thousands, e.g. 2.5k
+func parseAmount(amount string) (float64, error) {
+	multiplier := 1.0
+	if trimmed := strings.TrimRight(amount, "kK"); len(trimmed) == len(amount)-1 {
+		amount, multiplier = trimmed, 1000
+	}
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0, err
+	}
+	value *= multiplier
+	if math.IsNaN(value) || math.IsInf(value, 0) || value < 0 {
+		return 0, fmt.Errorf("amount must be a finite number of at least 0, got %q", amount)
+	}
+	return value, nil
+}
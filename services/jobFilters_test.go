@@ -0,0 +1,49 @@
+package services
+
+import (
+	"job-portal/repositories"
+	"testing"
+)
+
+func TestParseSalaryRange(t *testing.T) {
+	tests := []struct {
+		salaryRange string
+		min, max    float64 // max 0 means open-ended
+		valid       bool
+	}{
+		{"50000-100000", 50000, 100000, true},
+		{"0-2.5k", 0, 2500, true},
+		{"150k+", 150000, 0, true},
+		{"100-50", 0, 0, false},
+		{"NaN-100", 0, 0, false},
+		{"0-NaN", 0, 0, false},
+		{"NaN+", 0, 0, false},
+		{"0-Inf", 0, 0, false},
+		{"Infk+", 0, 0, false},
+		{"1e308k+", 0, 0, false}, // Overflows to +Inf
+		{"-5+", 0, 0, false},
+		{"abc-100", 0, 0, false},
+		{"100", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		var filter repositories.JobFilter
+		err := parseSalaryRange(tt.salaryRange, &filter)
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("parseSalaryRange(%q) accepted an invalid range", tt.salaryRange)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseSalaryRange(%q) = %v", tt.salaryRange, err)
+			continue
+		}
+		if filter.MinSalary == nil || *filter.MinSalary != tt.min {
+			t.Errorf("parseSalaryRange(%q) min = %v, want %v", tt.salaryRange, filter.MinSalary, tt.min)
+		}
+		if tt.max == 0 && filter.MaxSalary != nil || tt.max != 0 && (filter.MaxSalary == nil || *filter.MaxSalary != tt.max) {
+			t.Errorf("parseSalaryRange(%q) max = %v, want %v", tt.salaryRange, filter.MaxSalary, tt.max)
+		}
+	}
+}
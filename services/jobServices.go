@@ -2,254 +2,635 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"fmt"
+	"job-portal/apperrors"
+	"job-portal/currency"
+	"job-portal/geo"
 	"job-portal/models"
+	"job-portal/policy"
+	"job-portal/repositories"
+	"job-portal/utils"
 	"math"
-	"strconv"
 	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrNotJobOwner is returned when a user tries to change a job they did not post
+var ErrNotJobOwner = apperrors.Forbidden("only the job owner, its organization or an admin can modify this job")
+
+// ErrNotCompanyOwner is returned when a user posts a job for a company they do not manage
+var ErrNotCompanyOwner = apperrors.Forbidden("only the company owner, its organization or an admin can post jobs for this company")
+
+// ErrJobModified is returned when a job changed since the version the client last read
+var ErrJobModified = apperrors.PreconditionFailed("the job was modified by someone else, reload it and try again")
+
+// ErrInvalidCursor is returned for a pagination cursor that was not issued by this server
+var ErrInvalidCursor = apperrors.BadRequest("invalid pagination cursor")
+
+const (
+	// MaxPageSize caps how many jobs one page holds; larger page sizes are clamped
+	MaxPageSize = 100
+	// MaxCountedJobs caps how far listing totals are counted, since counting every
+	// match of a broad filter costs as much as reading them
+	MaxCountedJobs = 10000
+)
+
+// ListOptions selects the order and the page of a job listing
+type ListOptions struct {
+	Sort      string // One of repositories.JobSorts; defaults to relevance when searching, newest first otherwise
+	Page      int
+	PageSize  int
+	Cursor    string // The nextCursor of the previous page; Page is ignored when set
+	WithTotal bool   // Count the matching jobs, which the cursor-based clients rarely need
+}
+
+// jobCursor is the content of a pagination cursor
+type jobCursor struct {
+	Sort string `json:"sort"`
+	repositories.JobPosition
+}
+
 type JobService struct {
-	Collection *mongo.Collection
+	Repo      repositories.JobRepository
+	Cursor    *utils.Signer   // Signs pagination cursors so clients cannot forge positions
+	Rates     *currency.Rates // Converts salaries to annual amounts in the base currency
+	Geocoder  geo.Geocoder    // Places job locations on the map for near searches
+	Companies repositories.CompanyRepository
+	Orgs      OrgMembers // Lets teammates manage the jobs and companies of their organization
 }
 
 // NewJobService creates a new instance of JobService
-func NewJobService(collection *mongo.Collection) *JobService {
-	return &JobService{Collection: collection}
+func NewJobService(repo repositories.JobRepository, cursorSigner *utils.Signer, rates *currency.Rates, geocoder geo.Geocoder, companies repositories.CompanyRepository, orgs OrgMembers) *JobService {
+	return &JobService{Repo: repo, Cursor: cursorSigner, Rates: rates, Geocoder: geocoder, Companies: companies, Orgs: orgs}
 }
 
-// CreateJob adds a new job to the database
-func (s *JobService) CreateJob(job *models.Job) error {
+// CreateJob adds a new job to the database as a draft owned by the given user,
+// for a company the user manages. The job belongs to orgID, the user's active
+// organization, or else to the company's organization, if any.
+func (s *JobService) CreateJob(job *models.Job, userID, role, orgID string) error {
+	ownerID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return apperrors.InvalidID("invalid user ID format")
+	}
+
 	job.ID = primitive.NewObjectID()
+	job.CreatedBy = ownerID
 	job.CreatedAt = time.Now()
 	job.UpdatedAt = job.CreatedAt
-	job.PostedAt = time.Now() // Assume posted immediately
-	_, err := s.Collection.InsertOne(context.TODO(), job)
-	return err
+	job.Version = 1
+	job.Status = models.JobStatusDraft
+	job.PostedAt = time.Time{} // Set when the job is published
+	job.Score = 0
+	job.DistanceKm = nil
+	job.OrgID = primitive.NilObjectID
+	if orgID != "" {
+		if job.OrgID, err = primitive.ObjectIDFromHex(orgID); err != nil {
+			return apperrors.InvalidID("invalid organization ID format")
+		}
+	}
+	if err := s.attachCompany(job, userID, role); err != nil {
+		return err
+	}
+	if err := s.normalizeSalary(job); err != nil {
+		return err
+	}
+	if err := s.locate(job); err != nil {
+		return err
+	}
+	return s.Repo.Create(context.TODO(), job)
 }
 
 // GetJob retrieves a job by its ID
 func (s *JobService) GetJob(id string) (*models.Job, error) {
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return nil, errors.New("invalid job ID format")
+		return nil, apperrors.InvalidID("invalid job ID format")
 	}
 
-	var job models.Job
-	err = s.Collection.FindOne(context.TODO(), bson.M{"_id": objID}).Decode(&job)
+	job, err := s.Repo.FindByID(context.TODO(), objID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, apperrors.NotFound("job not found")
+	}
 	if err != nil {
-		return nil, errors.New("job not found")
+		return nil, err
 	}
 
-	return &job, nil
+	return job, nil
 }
 
+// GetVisibleJob retrieves a job for the given user. Only open jobs are public:
+// drafts, scheduled, paused, closed and expired jobs are shown to those who
+// may modify them.
+func (s *JobService) GetVisibleJob(id, userID, role string) (*models.Job, error) {
+	job, err := s.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.IsOpen(time.Now()) {
+		return job, nil
+	}
+	allowed, err := s.canModify(job, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, apperrors.NotFound("job not found")
+	}
+	return job, nil
+}
 
+// PublishJob makes a job public at postedAt, or right away if postedAt is zero.
+// A future postedAt schedules the job: it stays out of listings until then.
+func (s *JobService) PublishJob(id, userID, role string, postedAt time.Time, ifMatch []int64) (*models.Job, error) {
+	now := time.Now()
+	if postedAt.IsZero() {
+		postedAt = now
+	}
+	if postedAt.Before(now.Add(-time.Minute)) {
+		return nil, apperrors.Validation("invalid publishing time", map[string]string{"posted_at": "must not be in the past"})
+	}
 
-// UpdateJob updates an existing job and returns the updated job
-func (s *JobService) UpdateJob(id string, updateData map[string]interface{}) (*models.Job, error) {
-	objID, err := primitive.ObjectIDFromHex(id)
+	return s.transition(id, userID, role, models.JobStatusPublished, ifMatch, func(job *models.Job) error {
+		if !job.ApplyBy.IsZero() && !job.ApplyBy.After(postedAt) {
+			return apperrors.Validation("the job would expire before it is posted", map[string]string{"apply_by": "must be after the publishing time"})
+		}
+		job.PostedAt = postedAt
+		return nil
+	})
+}
+
+// PauseJob takes a published job out of listings until it is published again
+func (s *JobService) PauseJob(id, userID, role string, ifMatch []int64) (*models.Job, error) {
+	return s.transition(id, userID, role, models.JobStatusPaused, ifMatch, nil)
+}
+
+// CloseJob permanently stops a job from taking applications
+func (s *JobService) CloseJob(id, userID, role string, ifMatch []int64) (*models.Job, error) {
+	return s.transition(id, userID, role, models.JobStatusClosed, ifMatch, nil)
+}
+
+// transition moves a job to status if the lifecycle allows it. prepare may
+// adjust other fields of the job before it is written.
+func (s *JobService) transition(id, userID, role, status string, ifMatch []int64, prepare func(*models.Job) error) (*models.Job, error) {
+	job, err := s.GetJob(id)
 	if err != nil {
-		return nil, errors.New("invalid job ID format")
+		return nil, err
+	}
+	if err := s.checkCanModify(job, userID, role); err != nil {
+		return nil, err
+	}
+	if !utils.MatchesVersion(ifMatch, job.Version) {
+		return nil, ErrJobModified
+	}
+	if !models.CanTransitionJob(job.CurrentStatus(), status) {
+		return nil, apperrors.Conflict("a %s job cannot be moved to %s", job.CurrentStatus(), status)
 	}
 
+	job.Status = status
+	if prepare != nil {
+		if err := prepare(job); err != nil {
+			return nil, err
+		}
+	}
+
+	// Written against the version read above, so a concurrent change makes this fail
+	updated, err := s.Repo.Update(context.TODO(), job.ID, job.Version, bson.M{"status": job.Status, "posted_at": job.PostedAt})
+	if err != nil {
+		return nil, jobWriteError(err)
+	}
+	return updated, nil
+}
+
+// canModify reports whether the user may change the job: with job:update:any any
+// job, with job:update:own the jobs they created, and as a member of its
+// organization any job of that organization
+func (s *JobService) canModify(job *models.Job, userID, role string) (bool, error) {
+	return canActOn(s.Orgs, job.CreatedBy, job.OrgID, userID, role, policy.JobUpdate)
+}
+
+// checkCanModify returns ErrNotJobOwner if the user may not change the job
+func (s *JobService) checkCanModify(job *models.Job, userID, role string) error {
+	allowed, err := s.canModify(job, userID, role)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrNotJobOwner
+	}
+	return nil
+}
+
+// editableJobFields lists the JSON fields a merge patch may change. Everything
+// else, such as the ID, the owner and the timestamps, is managed by the server.
+var editableJobFields = map[string]bool{
+	"title":            true,
+	"description":      true,
+	"location":         true,
+	"min_salary":       true,
+	"max_salary":       true,
+	"currency":         true,
+	"period":           true,
+	"type":             true,
+	"experience":       true,
+	"education":        true,
+	"skills":           true,
+	"responsibilities": true,
+	"benefits":         true,
+	"apply_link":       true,
+	"apply_by":         true,
+	"company_id":       true,
+	"work_location":    true,
+}
+
+// readOnlyJobFields are stored with the job but never written by an update
+var readOnlyJobFields = []string{"_id", "created_by", "created_at", "updated_at", "version"}
+
+// UpdateJob applies a JSON Merge Patch (RFC 7396) to a job. The merged job must
+// pass validate, the same check a new job goes through, before it is saved.
+// The job must be at one of the ifMatch versions; nil accepts any version.
+func (s *JobService) UpdateJob(id, userID, role string, ifMatch []int64, patch map[string]interface{}, validate func(interface{}) error) (*models.Job, error) {
 	// Retrieve the current job before updating
-	var job models.Job
-	err = s.Collection.FindOne(context.TODO(), bson.M{"_id": objID}).Decode(&job)
+	job, err := s.GetJob(id)
 	if err != nil {
-		return nil, errors.New("job not found")
+		return nil, err
+	}
+	if err := s.checkCanModify(job, userID, role); err != nil {
+		return nil, err
+	}
+	if !utils.MatchesVersion(ifMatch, job.Version) {
+		return nil, ErrJobModified
+	}
+
+	rejected := map[string]string{}
+	for key := range patch {
+		if !editableJobFields[key] {
+			rejected[key] = "cannot be changed"
+		}
+	}
+	if len(rejected) > 0 {
+		return nil, apperrors.Validation("patch contains fields that cannot be changed", rejected)
 	}
 
-	// Update the job
-	update := bson.M{
-		"$set": updateData,
-		"$currentDate": bson.M{
-			"updated_at": true,
-		},
+	merged, err := mergeJob(job, patch)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(merged); err != nil {
+		return nil, err
+	}
+	if _, moved := patch["company_id"]; moved {
+		if err := s.attachCompany(merged, userID, role); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.normalizeSalary(merged); err != nil {
+		return nil, err
 	}
-	_, err = s.Collection.UpdateOne(context.TODO(), bson.M{"_id": objID}, update)
+	if _, moved := patch["location"]; moved || merged.Geo == nil {
+		if err := s.locate(merged); err != nil {
+			return nil, err
+		}
+	}
+
+	fields, err := jobFields(merged)
 	if err != nil {
 		return nil, err
 	}
+	if merged.Geo == nil {
+		fields["geo"] = nil // Clear the point of a location that can no longer be geocoded
+	}
 
-	// Retrieve the updated job
-	err = s.Collection.FindOne(context.TODO(), bson.M{"_id": objID}).Decode(&job)
+	// Update the job and retrieve the updated version. The repository checks the
+	// version again, atomically, in case another edit landed since it was read.
+	updated, err := s.Repo.Update(context.TODO(), job.ID, job.Version, fields)
 	if err != nil {
-		return nil, errors.New("failed to retrieve updated job")
+		return nil, jobWriteError(err)
 	}
 
-	return &job, nil
+	return updated, nil
 }
 
-// DeleteJob removes a job from the database and returns the deleted job
-func (s *JobService) DeleteJob(id string) (*models.Job, error) {
-	objID, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return nil, errors.New("invalid job ID format")
+// normalizeSalary checks the salary range, fills in the default currency and
+// period and computes the annual salaries in the base currency, which listings
+// filter and sort on
+func (s *JobService) normalizeSalary(job *models.Job) error {
+	if err := checkSalaryRange(job); err != nil {
+		return err
 	}
+	if job.Currency == "" {
+		job.Currency = s.Rates.Base
+	}
+	if job.Period == "" {
+		job.Period = models.PeriodYear
+	}
+	rate, ok := s.Rates.Rate(job.Currency)
+	if !ok {
+		return apperrors.Validation("unsupported salary currency", map[string]string{
+			"currency": "must be one of: " + strings.Join(s.Rates.Currencies(), ", "),
+		})
+	}
+	perYear := models.PeriodsPerYear[job.Period]
+	job.AnnualMinSalary = job.MinSalary * rate * perYear
+	job.AnnualMaxSalary = job.MaxSalary * rate * perYear
+	return nil
+}
 
-	// Retrieve the job before deleting
-	var job models.Job
-	err = s.Collection.FindOne(context.TODO(), bson.M{"_id": objID}).Decode(&job)
+// checkSalaryRange rejects negative salaries and a minimum above the maximum.
+// A zero maximum means the range is open-ended.
+func checkSalaryRange(job *models.Job) error {
+	problems := map[string]string{}
+	if job.MinSalary < 0 {
+		problems["min_salary"] = "must not be negative"
+	}
+	if job.MaxSalary < 0 {
+		problems["max_salary"] = "must not be negative"
+	}
+	if len(problems) == 0 && job.MaxSalary > 0 && job.MinSalary > job.MaxSalary {
+		problems["min_salary"] = "must not be greater than max_salary"
+	}
+	if len(problems) > 0 {
+		return apperrors.Validation("invalid salary range", problems)
+	}
+	return nil
+}
+
+// attachCompany checks that the user may post for the job's company and copies
+// the company's name and logo onto the job
+func (s *JobService) attachCompany(job *models.Job, userID, role string) error {
+	if job.CompanyID.IsZero() {
+		return apperrors.Validation("a job must be posted for a company", map[string]string{"company_id": "is required"})
+	}
+	company, err := s.Companies.FindByID(context.TODO(), job.CompanyID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return apperrors.Validation("unknown company", map[string]string{"company_id": "no company has this ID"})
+	}
+	if err != nil {
+		return err
+	}
+	allowed, err := canManageCompany(s.Orgs, company, userID, role)
 	if err != nil {
-		return nil, errors.New("job not found")
+		return err
+	}
+	if !allowed {
+		return ErrNotCompanyOwner
+	}
+	job.CompanyName, job.CompanyLogo = company.Name, company.Logo
+	if job.OrgID.IsZero() {
+		job.OrgID = company.OrgID
 	}
+	return nil
+}
 
-	// Delete the job
-	_, err = s.Collection.DeleteOne(context.TODO(), bson.M{"_id": objID})
+// locate geocodes the job's location. A location the geocoder does not know
+// leaves the job without a point: it is still listed, but not in near searches.
+func (s *JobService) locate(job *models.Job) error {
+	point, err := s.Geocoder.Geocode(context.TODO(), job.Location)
+	if errors.Is(err, geo.ErrNotFound) {
+		job.Geo = nil
+		return nil
+	}
 	if err != nil {
-		return nil, err
+		return err
 	}
+	job.Geo = models.NewGeoPoint(point.Lat, point.Lng)
+	return nil
+}
 
-	return &job, nil
+// NormalizeSalaries recomputes the annual salaries of stored jobs with the
+// current exchange rates, which may have changed since the jobs were saved
+func (s *JobService) NormalizeSalaries(ctx context.Context) (int64, error) {
+	return s.Repo.NormalizeSalaries(ctx, s.Rates)
 }
 
-// ListJobs retrieves jobs based on filters, search parameters, and pagination
-func (s *JobService) ListJobs(filter bson.M, page, pageSize int, search, datePosted, jobType, salaryRange, workLocation string) ([]models.Job, map[string]interface{}, error) {
-	// Apply the filters based on the provided parameters
-	var err error
-	filter, err = ApplyFilters(datePosted, jobType, salaryRange, workLocation, filter)
+// jobWriteError maps repository errors from a versioned write to domain errors
+func jobWriteError(err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		return apperrors.NotFound("job not found")
+	case errors.Is(err, repositories.ErrVersionConflict):
+		return ErrJobModified
+	}
+	return err
+}
+
+// mergeJob applies the patch to the JSON form of job and decodes the result
+func mergeJob(job *models.Job, patch map[string]interface{}) (*models.Job, error) {
+	data, err := json.Marshal(job)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
 	}
 
-	// Apply search filter if provided
-	if search != "" {
-		// Search in job title and description
-		searchRegex := bson.M{
-			"$regex":   search,
-			"$options": "i", // Case-insensitive search
-		}
-
-		// Combine search condition with other filters using $or
-		if existingFilter, ok := filter["$or"]; ok {
-			// If there's already an existing $or filter, append the search conditions
-			filter["$or"] = append(existingFilter.([]bson.M),
-				bson.M{"title": searchRegex},
-				bson.M{"description": searchRegex},
-			)
-		} else {
-			// If no $or filter exists, create a new $or filter for search
-			filter["$or"] = []bson.M{
-				{"title": searchRegex},
-				{"description": searchRegex},
-			}
+	data, err = json.Marshal(utils.MergePatch(document, patch))
+	if err != nil {
+		return nil, err
+	}
+	var merged models.Job
+	if err := json.Unmarshal(data, &merged); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, apperrors.Validation("patch contains values of the wrong type",
+				map[string]string{typeErr.Field: "must be a " + typeErr.Type.String()})
 		}
+		return nil, apperrors.BadRequest("invalid patch: %v", err)
 	}
+	return &merged, nil
+}
 
-	// Set pagination options
-	options := options.Find()
-	options.SetSkip(int64((page - 1) * pageSize)) // Skip for pagination
-	options.SetLimit(int64(pageSize))             // Limit results per page
-
-	// Fetch jobs from the collection
-	cursor, err := s.Collection.Find(context.TODO(), filter, options)
+// jobFields returns the stored fields of job that an update may write
+func jobFields(job *models.Job) (map[string]interface{}, error) {
+	data, err := bson.Marshal(job)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	fields := bson.M{}
+	if err := bson.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, key := range readOnlyJobFields {
+		delete(fields, key)
 	}
-	defer cursor.Close(context.TODO())
+	return fields, nil
+}
 
-	var jobs []models.Job
-	if err := cursor.All(context.TODO(), &jobs); err != nil {
-		return nil, nil, err
+// DeleteJob removes a job on behalf of the given user and returns the deleted job
+func (s *JobService) DeleteJob(id, userID, role string, ifMatch []int64) (*models.Job, error) {
+	// Retrieve the job before deleting
+	job, err := s.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCanModify(job, userID, role); err != nil {
+		return nil, err
+	}
+	if !utils.MatchesVersion(ifMatch, job.Version) {
+		return nil, ErrJobModified
 	}
 
-	// Count total items matching the filter
-	totalItems, err := s.Collection.CountDocuments(context.TODO(), filter)
+	// Delete the job
+	err = s.Repo.Delete(context.TODO(), job.ID, job.Version)
 	if err != nil {
-		return nil, nil, err
+		return nil, jobWriteError(err)
 	}
 
-	// Calculate total pages
-	totalPages := int(math.Ceil(float64(totalItems) / float64(pageSize)))
+	return job, nil
+}
 
-	// Prepare pagination data
-	pagination := map[string]interface{}{
-		"totalItems":  totalItems,
-		"totalPages":  totalPages,
-		"currentPage": page,
-		"pageSize":    pageSize,
-	}
+// ExpireJobs marks every published or paused job whose apply_by date has passed as expired
+func (s *JobService) ExpireJobs(ctx context.Context) (int64, error) {
+	return s.Repo.ExpireOverdue(ctx, time.Now())
+}
 
-	return jobs, pagination, nil
+// ListOpenJobs lists the jobs the public can see: published, posted and still taking applications
+func (s *JobService) ListOpenJobs(filter repositories.JobFilter, opts ListOptions) ([]models.Job, map[string]interface{}, error) {
+	filter.OpenAt = time.Now()
+	return s.ListJobs(filter, opts)
+}
+
+// OpenJobFacets counts the open jobs matching the filter by each value of the facets
+func (s *JobService) OpenJobFacets(filter repositories.JobFilter, facets []string) (map[string][]repositories.FacetCount, error) {
+	filter.OpenAt = time.Now()
+	return s.Repo.Facets(context.TODO(), filter, facets)
 }
 
-// ApplyFilters constructs the filter based on the provided parameters
-func ApplyFilters(datePosted, jobType, salaryRange, workLocation string, existingFilter bson.M) (bson.M, error) {
-	// Initialize the filter if it doesn't exist
-	if existingFilter == nil {
-		existingFilter = bson.M{}
+// ParseFacets reads the comma separated facets query parameter
+func ParseFacets(param string) ([]string, error) {
+	var facets []string
+	for _, facet := range strings.Split(param, ",") {
+		facet = strings.TrimSpace(facet)
+		if facet == "" {
+			continue
+		}
+		known := false
+		for _, valid := range repositories.JobFacets {
+			known = known || facet == valid
+		}
+		if !known {
+			return nil, apperrors.BadRequest("unknown facet %q, valid facets are: %s", facet, strings.Join(repositories.JobFacets, ", "))
+		}
+		facets = append(facets, facet)
 	}
+	return facets, nil
+}
 
-	// Assuming existingFilter is already declared and ready for use
-if datePosted == "anytime" {
-	// No filter, meaning all dates are accepted
-} else if datePosted != "" {
-	// Handle date range logic (last 7 days, last 30 days, last 24 hours, etc.)
-	var postedDate time.Time
-	switch datePosted {
-	case "last_7_days":
-		postedDate = time.Now().AddDate(0, 0, -7)
-	case "last_24_hours":
-		postedDate = time.Now().Add(-24 * time.Hour) // Subtract 24 hours from the current time
-	case "last_30_days":
-		postedDate = time.Now().AddDate(0, 0, -30) // Default to last 30 days
+// ListOwnJobs lists the jobs posted by the user in any status, drafts included,
+// optionally narrowed down to one status
+func (s *JobService) ListOwnJobs(userID, status string, opts ListOptions) ([]models.Job, map[string]interface{}, error) {
+	ownerID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, nil, apperrors.InvalidID("invalid user ID format")
 	}
-	// Add the date filter to the existing filter
-	existingFilter["posted_at"] = bson.M{"$gte": postedDate}
+	return s.ListJobs(repositories.JobFilter{CreatedBy: ownerID, Status: status}, opts)
 }
 
-	// Job Type filter (full-time, part-time, etc.)
-	if jobType != "" {
-		existingFilter["type"] = jobType
+// ListOrgJobs lists the jobs of an organization in any status, drafts included,
+// optionally narrowed down to one status. The caller checks the membership.
+func (s *JobService) ListOrgJobs(orgID, status string, opts ListOptions) ([]models.Job, map[string]interface{}, error) {
+	orgObjID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return nil, nil, apperrors.InvalidID("invalid organization ID format")
 	}
+	return s.ListJobs(repositories.JobFilter{OrgID: orgObjID, Status: status}, opts)
+}
 
-		// Salary Range filter (e.g., "50000-100000")
-if salaryRange != "" {
-    // Split the salary range by "-"
-    salaryRangeSplit := strings.Split(salaryRange, "-")
-    if len(salaryRangeSplit) == 2 {
-        // Convert the salary values to float64
-        minSalary, err := strconv.ParseFloat(salaryRangeSplit[0], 64)
-        if err != nil {
-            return nil, fmt.Errorf("invalid min salary: %v", err)
-        }
-        maxSalary, err := strconv.ParseFloat(salaryRangeSplit[1], 64)
-        if err != nil {
-            return nil, fmt.Errorf("invalid max salary: %v", err)
-        }
+// ListJobs retrieves jobs matching the filter one page at a time, either by page
+// number or by following the nextCursor of the previous page. Cursors stay stable
+// while jobs are added or removed, whereas page numbers shift.
+func (s *JobService) ListJobs(filter repositories.JobFilter, opts ListOptions) ([]models.Job, map[string]interface{}, error) {
+	sort, err := jobSort(opts.Sort, filter.Search)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+	if opts.PageSize > MaxPageSize {
+		opts.PageSize = MaxPageSize
+	}
 
-        fmt.Println(minSalary, maxSalary) // Debugging: check the parsed values
+	// Ask for one extra job to learn whether there is a next page
+	query := repositories.JobQuery{Sort: sort, Limit: int64(opts.PageSize) + 1}
+	if opts.Cursor != "" {
+		var cursor jobCursor
+		if err := s.Cursor.Verify(opts.Cursor, &cursor); err != nil {
+			return nil, nil, ErrInvalidCursor
+		}
+		if cursor.Sort != sort {
+			return nil, nil, apperrors.BadRequest("the cursor belongs to a listing sorted by %q, not %q", cursor.Sort, sort)
+		}
+		query.After = &cursor.JobPosition
+	} else {
+		query.Skip = int64((opts.Page - 1) * opts.PageSize)
+	}
 
-        // Add the salary filter to the existing filter
-        existingFilter["$and"] = []bson.M{
-            {
-                "min_salary": bson.M{"$gte": minSalary},
-            },
-            {
-                "max_salary": bson.M{"$lte": maxSalary},
-            },
-        }
-    } else {
-        return nil, fmt.Errorf("invalid salary range format: %s", salaryRange)
-    }
-}
+	jobs, err := s.Repo.List(context.TODO(), filter, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	if filter.Near != nil {
+		for i := range jobs {
+			if geoPoint := jobs[i].Geo; geoPoint != nil && len(geoPoint.Coordinates) == 2 {
+				distance := geo.DistanceKm(*filter.Near, geo.Point{Lat: geoPoint.Coordinates[1], Lng: geoPoint.Coordinates[0]})
+				distance = math.Round(distance*10) / 10
+				jobs[i].DistanceKm = &distance
+			}
+		}
+	}
 
+	// Prepare pagination data
+	pagination := map[string]interface{}{
+		"pageSize": opts.PageSize,
+		"sort":     sort,
+		"hasMore":  len(jobs) > opts.PageSize,
+	}
+	if opts.Cursor == "" {
+		pagination["currentPage"] = opts.Page
+	}
+	if len(jobs) > opts.PageSize {
+		jobs = jobs[:opts.PageSize]
+		next, err := s.Cursor.Sign(jobCursor{Sort: sort, JobPosition: repositories.PositionOf(&jobs[len(jobs)-1], sort)})
+		if err != nil {
+			return nil, nil, err
+		}
+		pagination["nextCursor"] = next
+	}
 
+	if opts.WithTotal {
+		totalItems, err := s.Repo.Count(context.TODO(), filter, MaxCountedJobs)
+		if err != nil {
+			return nil, nil, err
+		}
+		pagination["totalItems"] = totalItems
+		pagination["totalPages"] = int(math.Ceil(float64(totalItems) / float64(opts.PageSize)))
+		// At the cap the real total is unknown, only that it is at least this many
+		pagination["totalCapped"] = totalItems >= MaxCountedJobs
+	}
 
+	return jobs, pagination, nil
+}
 
-	// Work Location filter (on-site, remote, hybrid)
-	if workLocation != "" {
-		existingFilter["work_location"] = workLocation
+// jobSort validates a sort order, defaulting to relevance for searches and to newest first otherwise
+func jobSort(sort, search string) (string, error) {
+	searching := repositories.ParseTextQuery(search).HasPositive()
+	switch {
+	case sort == "" && searching:
+		return repositories.SortRelevance, nil
+	case sort == "":
+		return repositories.SortPostedAtDesc, nil
+	case sort == repositories.SortRelevance && !searching:
+		return "", apperrors.BadRequest("sorting by relevance requires a search")
 	}
-
-	// Return the updated filter
-	return existingFilter, nil
+	for _, valid := range repositories.JobSorts {
+		if sort == valid {
+			return sort, nil
+		}
+	}
+	return "", apperrors.BadRequest("unknown sort %q, valid sorts are: %s", sort, strings.Join(repositories.JobSorts, ", "))
 }
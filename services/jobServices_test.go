@@ -0,0 +1,381 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"job-portal/apperrors"
+	"job-portal/models"
+	"job-portal/repositories"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// seedJobs stores a fixed set of jobs the way CreateJob and PublishJob would
+// leave them, with posting times and statuses the tests control
+func seedJobs(t *testing.T, s *JobService) {
+	t.Helper()
+	base := time.Now().Add(-48 * time.Hour)
+	jobs := []models.Job{
+		{Title: "Go Backend Engineer", Location: "Dhaka, Bangladesh", Type: "full-time", Experience: "senior", Education: "master",
+			WorkLocation: models.OnSite, Skills: []string{"Go", "MongoDB"}, MinSalary: 60000, MaxSalary: 90000, PostedAt: base},
+		{Title: "Frontend Developer", Location: "Dhaka", Type: "full-time", Experience: "mid", Education: "bachelor",
+			WorkLocation: models.Hybrid, Skills: []string{"React", "TypeScript"}, MinSalary: 3000, MaxSalary: 4000, Currency: "EUR", Period: models.PeriodMonth,
+			PostedAt: base}, // Posted at the same time as the first job
+		{Title: "Data Scientist", Location: "London, UK", Type: "contract", Experience: "senior", Education: "phd",
+			WorkLocation: models.Remote, Skills: []string{"Python", "Go"}, MinSalary: 40000, MaxSalary: 60000, Currency: "GBP", PostedAt: base.Add(time.Hour)},
+		{Title: "Junior Go Developer", Location: "Chittagong", Type: "part-time", Experience: "entry", Education: "bachelor",
+			WorkLocation: models.OnSite, Skills: []string{"Go"}, PostedAt: base.Add(2 * time.Hour)}, // No salary
+		{Title: "Project Manager", Location: "Paris, France", Type: "full-time", Experience: "mid", Education: "master",
+			WorkLocation: models.OnSite, Skills: []string{"Agile"}, PostedAt: base.Add(3 * time.Hour)}, // No salary
+		{Title: "Draft Go Role", Location: "Dhaka", Type: "full-time", Experience: "mid", Education: "master",
+			WorkLocation: models.OnSite, Skills: []string{"Go"}, Status: models.JobStatusDraft},
+		{Title: "Scheduled Go Role", Location: "Dhaka", Type: "full-time", Experience: "mid", Education: "master",
+			WorkLocation: models.OnSite, Skills: []string{"Go"}, PostedAt: time.Now().Add(time.Hour)},
+		{Title: "Closed Go Role", Location: "Dhaka", Type: "full-time", Experience: "mid", Education: "master",
+			WorkLocation: models.OnSite, Skills: []string{"Go"}, PostedAt: base, Status: models.JobStatusClosed},
+	}
+	for i := range jobs {
+		job := &jobs[i]
+		job.ID = primitive.NewObjectID()
+		job.Description = job.Title + " at a growing company"
+		job.Version = 1
+		if job.Status == "" {
+			job.Status = models.JobStatusPublished
+		}
+		if err := s.normalizeSalary(job); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.locate(job); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Repo.Create(context.Background(), job); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestListOpenJobsFilters(t *testing.T) {
+	s := newFixture(t).jobs
+	seedJobs(t, s)
+
+	open := []string{"Go Backend Engineer", "Frontend Developer", "Data Scientist", "Junior Go Developer", "Project Manager"}
+	tests := []struct {
+		name   string
+		params JobFilterParams
+		want   []string
+	}{
+		{"no filter lists the open jobs", JobFilterParams{}, open},
+		{"job type", JobFilterParams{JobType: "full-time"}, []string{"Go Backend Engineer", "Frontend Developer", "Project Manager"}},
+		{"work locations", JobFilterParams{WorkLocation: "remote,hybrid"}, []string{"Frontend Developer", "Data Scientist"}},
+		{"any skill ignores case", JobFilterParams{Skills: "go,react"}, []string{"Go Backend Engineer", "Frontend Developer", "Data Scientist", "Junior Go Developer"}},
+		{"all skills", JobFilterParams{Skills: "go,mongodb", SkillsMatch: "all"}, []string{"Go Backend Engineer"}},
+		{"location", JobFilterParams{Location: "dhaka"}, []string{"Go Backend Engineer", "Frontend Developer"}},
+		{"open-ended salary", JobFilterParams{SalaryRange: "55k+"}, []string{"Go Backend Engineer", "Data Scientist"}},
+		{"salary in another currency", JobFilterParams{SalaryRange: "0-40k", Currency: "EUR"}, []string{"Frontend Developer", "Junior Go Developer", "Project Manager"}},
+		{"near includes remote jobs", JobFilterParams{Near: "23.81,90.41"}, []string{"Go Backend Engineer", "Frontend Developer", "Data Scientist"}},
+		{"near without remote jobs", JobFilterParams{Near: "23.81,90.41", Remote: "exclude"}, []string{"Go Backend Engineer", "Frontend Developer"}},
+		{"wider radius", JobFilterParams{Near: "23.81,90.41", RadiusKm: "300", Remote: "exclude"}, []string{"Go Backend Engineer", "Frontend Developer", "Junior Go Developer"}},
+		{"search", JobFilterParams{Search: "go"}, []string{"Go Backend Engineer", "Data Scientist", "Junior Go Developer"}},
+		{"search with exclusion", JobFilterParams{Search: "go -junior"}, []string{"Go Backend Engineer", "Data Scientist"}},
+		{"phrase search", JobFilterParams{Search: `"project manager"`}, []string{"Project Manager"}},
+		{"search and filter", JobFilterParams{Search: "go", Experience: "senior", JobType: "contract"}, []string{"Data Scientist"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseJobFilter(tt.params, s.Rates)
+			if err != nil {
+				t.Fatal(err)
+			}
+			jobs, pagination, err := s.ListOpenJobs(filter, ListOptions{PageSize: 20, WithTotal: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := titles(jobs); !sameSet(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if total := pagination["totalItems"]; total != int64(len(tt.want)) {
+				t.Errorf("totalItems = %v, want %d", total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestListOpenJobsRelevance(t *testing.T) {
+	s := newFixture(t).jobs
+	seedJobs(t, s)
+
+	jobs, pagination, err := s.ListOpenJobs(repositories.JobFilter{Search: "go"}, ListOptions{PageSize: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pagination["sort"] != repositories.SortRelevance {
+		t.Errorf("a search sorts by %v, want relevance", pagination["sort"])
+	}
+	for i := 1; i < len(jobs); i++ {
+		if jobs[i].Score > jobs[i-1].Score {
+			t.Errorf("%q (score %v) comes after %q (score %v)", jobs[i].Title, jobs[i].Score, jobs[i-1].Title, jobs[i-1].Score)
+		}
+	}
+	if last := jobs[len(jobs)-1].Title; last != "Data Scientist" {
+		t.Errorf("the job matching only in its skills should come last, got %q", last)
+	}
+
+	if _, _, err := s.ListOpenJobs(repositories.JobFilter{}, ListOptions{Sort: repositories.SortRelevance}); err == nil {
+		t.Error("sorting by relevance without a search was accepted")
+	}
+}
+
+// TestCursorPagesMatchNumberedPages walks every sort order by cursor and by page
+// number and checks both see every job once, in the order of a single page
+func TestCursorPagesMatchNumberedPages(t *testing.T) {
+	s := newFixture(t).jobs
+	seedJobs(t, s)
+
+	for _, tt := range []struct {
+		sort   string
+		search string
+	}{
+		{repositories.SortPostedAtDesc, ""},
+		{repositories.SortPostedAtAsc, ""},
+		{repositories.SortSalaryAsc, ""}, // Jobs without a salary tie at 0
+		{repositories.SortSalaryDesc, ""},
+		{repositories.SortRelevance, "go developer"},
+	} {
+		t.Run(tt.sort, func(t *testing.T) {
+			filter := repositories.JobFilter{Search: tt.search}
+			all, _, err := s.ListOpenJobs(filter, ListOptions{Sort: tt.sort, PageSize: 100})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(all) < 3 {
+				t.Fatalf("only %d jobs to page through", len(all))
+			}
+
+			var byCursor []string
+			opts := ListOptions{Sort: tt.sort, PageSize: 2}
+			for page := 0; ; page++ {
+				if page > len(all) {
+					t.Fatal("the cursor never ran out")
+				}
+				jobs, pagination, err := s.ListOpenJobs(filter, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				byCursor = append(byCursor, ids(jobs)...)
+				next, ok := pagination["nextCursor"].(string)
+				if ok != pagination["hasMore"].(bool) {
+					t.Fatalf("hasMore = %v but nextCursor present = %v", pagination["hasMore"], ok)
+				}
+				if !ok {
+					break
+				}
+				opts.Cursor = next
+			}
+
+			var byPage []string
+			for page := 1; page <= (len(all)+1)/2; page++ {
+				jobs, _, err := s.ListOpenJobs(filter, ListOptions{Sort: tt.sort, PageSize: 2, Page: page})
+				if err != nil {
+					t.Fatal(err)
+				}
+				byPage = append(byPage, ids(jobs)...)
+			}
+
+			want := ids(all)
+			if !equalStrings(byCursor, want) {
+				t.Errorf("cursor pages = %v, want %v", byCursor, want)
+			}
+			if !equalStrings(byPage, want) {
+				t.Errorf("numbered pages = %v, want %v", byPage, want)
+			}
+		})
+	}
+}
+
+func TestCursorSurvivesNewJobs(t *testing.T) {
+	s := newFixture(t).jobs
+	seedJobs(t, s)
+
+	first, pagination, err := s.ListOpenJobs(repositories.JobFilter{}, ListOptions{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A newer job goes to the front of the listing, and must not shift the next page
+	job := &models.Job{ID: primitive.NewObjectID(), Title: "Brand New Role", Location: "Dhaka", Status: models.JobStatusPublished, PostedAt: time.Now().Add(-time.Minute), Version: 1}
+	if err := s.Repo.Create(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+
+	second, _, err := s.ListOpenJobs(repositories.JobFilter{}, ListOptions{PageSize: 2, Cursor: pagination["nextCursor"].(string)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, _, err := s.ListOpenJobs(repositories.JobFilter{}, ListOptions{PageSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := append(ids(first), ids(second)...), ids(all)[1:5]; !equalStrings(got, want) {
+		t.Errorf("pages = %v, want %v", got, want)
+	}
+}
+
+func TestInvalidCursors(t *testing.T) {
+	s := newFixture(t).jobs
+	seedJobs(t, s)
+
+	_, pagination, err := s.ListOpenJobs(repositories.JobFilter{}, ListOptions{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor := pagination["nextCursor"].(string)
+
+	if _, _, err := s.ListOpenJobs(repositories.JobFilter{}, ListOptions{PageSize: 2, Cursor: cursor + "x"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("a tampered cursor gave %v, want ErrInvalidCursor", err)
+	}
+	if _, _, err := s.ListOpenJobs(repositories.JobFilter{}, ListOptions{PageSize: 2, Cursor: cursor, Sort: repositories.SortSalaryAsc}); err == nil {
+		t.Error("a cursor was accepted for another sort order")
+	}
+}
+
+func TestCreatePublishAndList(t *testing.T) {
+	f := newFixture(t)
+	s := f.jobs
+	userID := primitive.NewObjectID()
+	company := f.company(t, userID, primitive.NilObjectID)
+
+	job := &models.Job{
+		Title: "Platform Engineer", Description: "Runs the platform", Location: "Gulshan, Dhaka, Bangladesh",
+		MinSalary: 5000, MaxSalary: 6000, Period: models.PeriodMonth, Type: "full-time", Experience: "mid",
+		Education: "bachelor", WorkLocation: models.OnSite, CompanyID: company.ID,
+	}
+	if err := s.CreateJob(job, userID.Hex(), models.RoleRecruiter, ""); err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != models.JobStatusDraft || job.CompanyName != "Acme" || job.AnnualMaxSalary != 72000 || job.Geo == nil {
+		t.Fatalf("created job = %+v", job)
+	}
+
+	open := func() []string {
+		jobs, _, err := s.ListOpenJobs(repositories.JobFilter{}, ListOptions{PageSize: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return titles(jobs)
+	}
+	if got := open(); len(got) != 0 {
+		t.Errorf("a draft is listed: %v", got)
+	}
+	own, _, err := s.ListOwnJobs(userID.Hex(), "", ListOptions{PageSize: 10})
+	if err != nil || len(own) != 1 {
+		t.Fatalf("own jobs = %v, %v", titles(own), err)
+	}
+
+	if _, err := s.PublishJob(job.ID.Hex(), primitive.NewObjectID().Hex(), models.RoleRecruiter, time.Time{}, nil); !errors.Is(err, ErrNotJobOwner) {
+		t.Errorf("another recruiter publishing got %v, want ErrNotJobOwner", err)
+	}
+	if _, err := s.PublishJob(job.ID.Hex(), userID.Hex(), models.RoleRecruiter, time.Time{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := open(); !equalStrings(got, []string{"Platform Engineer"}) {
+		t.Errorf("open jobs = %v", got)
+	}
+}
+
+func TestGetVisibleJob(t *testing.T) {
+	s := newFixture(t).jobs
+	ownerID := primitive.NewObjectID()
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	byTitle := map[string]string{}
+	for _, job := range []*models.Job{
+		{Title: "Open", Status: models.JobStatusPublished, PostedAt: past},
+		{Title: "Draft", Status: models.JobStatusDraft},
+		{Title: "Scheduled", Status: models.JobStatusPublished, PostedAt: future},
+		{Title: "Paused", Status: models.JobStatusPaused, PostedAt: past},
+		{Title: "Closed", Status: models.JobStatusClosed, PostedAt: past},
+		{Title: "Expired", Status: models.JobStatusExpired, PostedAt: past},
+		{Title: "Past its deadline", Status: models.JobStatusPublished, PostedAt: past, ApplyBy: past},
+	} {
+		job.ID = primitive.NewObjectID()
+		job.CreatedBy = ownerID
+		if err := s.Repo.Create(context.Background(), job); err != nil {
+			t.Fatal(err)
+		}
+		byTitle[job.Title] = job.ID.Hex()
+	}
+
+	stranger := primitive.NewObjectID().Hex()
+	tests := []struct {
+		title  string
+		userID string
+		role   string
+		want   bool
+	}{
+		{"Open", stranger, models.RoleUser, true},
+		{"Draft", stranger, models.RoleUser, false},
+		{"Scheduled", stranger, models.RoleUser, false},
+		{"Paused", stranger, models.RoleUser, false},
+		{"Closed", stranger, models.RoleUser, false},
+		{"Expired", stranger, models.RoleUser, false},
+		{"Past its deadline", stranger, models.RoleRecruiter, false},
+		{"Paused", ownerID.Hex(), models.RoleRecruiter, true},
+		{"Past its deadline", ownerID.Hex(), models.RoleRecruiter, true},
+		{"Closed", stranger, models.RoleAdmin, true},
+	}
+	for _, tt := range tests {
+		_, err := s.GetVisibleJob(byTitle[tt.title], tt.userID, tt.role)
+		if got := err == nil; got != tt.want {
+			t.Errorf("%s job as %s: err = %v, want visible %v", tt.title, tt.role, err, tt.want)
+		}
+	}
+}
+
+func TestSalaryRangeIsChecked(t *testing.T) {
+	f := newFixture(t)
+	s := f.jobs
+	userID := primitive.NewObjectID()
+	company := f.company(t, userID, primitive.NilObjectID)
+
+	create := func(min, max float64) error {
+		return s.CreateJob(&models.Job{Title: "Engineer", Location: "Dhaka", MinSalary: min, MaxSalary: max, CompanyID: company.ID},
+			userID.Hex(), models.RoleRecruiter, "")
+	}
+	tests := []struct {
+		min, max float64
+		field    string
+	}{
+		{1000, 2000, ""},
+		{2000, 2000, ""},
+		{1000, 0, ""}, // No upper bound
+		{-1, 2000, "min_salary"},
+		{1000, -1, "max_salary"},
+		{3000, 2000, "min_salary"},
+	}
+	for _, tt := range tests {
+		err := create(tt.min, tt.max)
+		if tt.field == "" {
+			if err != nil {
+				t.Errorf("salary %v-%v: %v", tt.min, tt.max, err)
+			}
+			continue
+		}
+		var appErr *apperrors.Error
+		if !errors.As(err, &appErr) || appErr.Fields[tt.field] == "" {
+			t.Errorf("salary %v-%v: err = %v, want a problem with %s", tt.min, tt.max, err, tt.field)
+		}
+	}
+
+	job := &models.Job{Title: "Engineer", Location: "Dhaka", MinSalary: 1000, MaxSalary: 2000, CompanyID: company.ID}
+	if err := s.CreateJob(job, userID.Hex(), models.RoleRecruiter, ""); err != nil {
+		t.Fatal(err)
+	}
+	patch := map[string]interface{}{"min_salary": 2500}
+	_, err := s.UpdateJob(job.ID.Hex(), userID.Hex(), models.RoleRecruiter, nil, patch, noValidation)
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || appErr.Fields["min_salary"] == "" {
+		t.Errorf("raising the minimum above the maximum got %v, want a problem with min_salary", err)
+	}
+}
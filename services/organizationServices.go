@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"job-portal/apperrors"
+	"job-portal/mailer"
+	"job-portal/models"
+	"job-portal/repositories"
+	"job-portal/utils"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrOrganizationNotFound = apperrors.NotFound("organization not found")
+	ErrOrganizationHasJobs  = apperrors.Conflict("the organization still has jobs, delete them first")
+	ErrMemberNotFound       = apperrors.NotFound("member not found")
+	ErrAlreadyMember        = apperrors.Conflict("you are already a member of this organization")
+	ErrInvitationNotFound   = apperrors.NotFound("invitation not found")
+	ErrInvalidInvitation    = apperrors.BadRequest("invalid or expired invitation")
+	ErrInvitationForOther   = apperrors.Forbidden("this invitation was sent to another email address")
+	ErrOwnerOnly            = apperrors.Forbidden("only the organization owner can do this")
+	ErrCannotRemoveOwner    = apperrors.Conflict("the owner cannot leave or be removed, transfer the ownership first")
+)
+
+// InvitationTTL is how long an emailed invitation can be accepted
+const InvitationTTL = 7 * 24 * time.Hour
+
+// UserOrganization is an organization together with the user's role in it
+type UserOrganization struct {
+	models.Organization `bson:",inline"`
+	Role                string `json:"role" bson:"role"`
+}
+
+type OrganizationService struct {
+	Repo        repositories.OrganizationRepository
+	Jobs        repositories.JobRepository
+	Mailer      mailer.Mailer
+	FrontendURL string // Base URL of the web app, used to build invitation links
+}
+
+// NewOrganizationService creates a new instance of OrganizationService
+func NewOrganizationService(repo repositories.OrganizationRepository, jobs repositories.JobRepository, mail mailer.Mailer, frontendURL string) *OrganizationService {
+	return &OrganizationService{Repo: repo, Jobs: jobs, Mailer: mail, FrontendURL: frontendURL}
+}
+
+// CreateOrganization adds a new organization with the given user as its owner
+func (s *OrganizationService) CreateOrganization(org *models.Organization, userID string) error {
+	ownerID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return apperrors.InvalidID("invalid user ID format")
+	}
+
+	org.ID = primitive.NewObjectID()
+	org.CreatedBy = ownerID
+	org.CreatedAt = time.Now()
+	org.UpdatedAt = org.CreatedAt
+	if err := s.Repo.Create(context.TODO(), org); err != nil {
+		return err
+	}
+	return s.addMember(org.ID, ownerID, models.OrgRoleOwner)
+}
+
+// GetOrganization retrieves an organization by its ID
+func (s *OrganizationService) GetOrganization(orgID string) (*models.Organization, error) {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return nil, apperrors.InvalidID("invalid organization ID format")
+	}
+
+	org, err := s.Repo.FindByID(context.TODO(), objID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// ListUserOrganizations retrieves the organizations the user belongs to, by name
+func (s *OrganizationService) ListUserOrganizations(userID string) ([]UserOrganization, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, apperrors.InvalidID("invalid user ID format")
+	}
+
+	memberships, err := s.Repo.ListUserMemberships(context.TODO(), userObjID)
+	if err != nil {
+		return nil, err
+	}
+	roles := map[primitive.ObjectID]string{}
+	var orgIDs []primitive.ObjectID
+	for _, membership := range memberships {
+		roles[membership.OrgID] = membership.Role
+		orgIDs = append(orgIDs, membership.OrgID)
+	}
+
+	orgs, err := s.Repo.FindByIDs(context.TODO(), orgIDs)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]UserOrganization, 0, len(orgs))
+	for _, org := range orgs {
+		result = append(result, UserOrganization{Organization: org, Role: roles[org.ID]})
+	}
+	return result, nil
+}
+
+// RenameOrganization changes the name of an organization
+func (s *OrganizationService) RenameOrganization(orgID, name string) (*models.Organization, error) {
+	org, err := s.GetOrganization(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.Repo.Update(context.TODO(), org.ID, map[string]interface{}{"name": name, "updated_at": time.Now()})
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrOrganizationNotFound
+	}
+	return updated, err
+}
+
+// DeleteOrganization removes an organization that no longer has jobs, with its
+// memberships and invitations. Its companies are left to the users who created them.
+func (s *OrganizationService) DeleteOrganization(orgID string) error {
+	org, err := s.GetOrganization(orgID)
+	if err != nil {
+		return err
+	}
+
+	jobs, err := s.Jobs.Count(context.TODO(), repositories.JobFilter{OrgID: org.ID}, 1)
+	if err != nil {
+		return err
+	}
+	if jobs > 0 {
+		return ErrOrganizationHasJobs
+	}
+
+	err = s.Repo.Delete(context.TODO(), org.ID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrOrganizationNotFound
+	}
+	return err
+}
+
+// MemberRole returns the user's role in the organization, or "" if the user is not a member
+func (s *OrganizationService) MemberRole(orgID, userID string) (string, error) {
+	membership, err := s.getMember(orgID, userID)
+	if errors.Is(err, ErrMemberNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return membership.Role, nil
+}
+
+// ListMembers retrieves the members of an organization, oldest first
+func (s *OrganizationService) ListMembers(orgID string) ([]models.Membership, error) {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return nil, apperrors.InvalidID("invalid organization ID format")
+	}
+	return s.Repo.ListMembers(context.TODO(), objID)
+}
+
+// ChangeMemberRole gives a member another role. Making a member the owner
+// transfers the ownership: the current owner becomes an admin.
+func (s *OrganizationService) ChangeMemberRole(orgID, ownerID, memberID, role string) (*models.Membership, error) {
+	owner, err := s.getMember(orgID, ownerID)
+	if errors.Is(err, ErrMemberNotFound) || (err == nil && owner.Role != models.OrgRoleOwner) {
+		return nil, ErrOwnerOnly
+	}
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.getMember(orgID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if member.ID == owner.ID {
+		return nil, apperrors.Conflict("the owner's role can only change by transferring the ownership")
+	}
+
+	if err := s.setRole(member, role); err != nil {
+		return nil, err
+	}
+	if role == models.OrgRoleOwner {
+		if err := s.setRole(owner, models.OrgRoleAdmin); err != nil {
+			return nil, err
+		}
+	}
+	return member, nil
+}
+
+// RemoveMember takes a user out of an organization. Members may leave on their
+// own; the owner removes anyone else, admins only plain members.
+func (s *OrganizationService) RemoveMember(orgID, actorID, actorRole, memberID string) error {
+	member, err := s.getMember(orgID, memberID)
+	if err != nil {
+		return err
+	}
+	if member.Role == models.OrgRoleOwner {
+		return ErrCannotRemoveOwner
+	}
+	if memberID != actorID && !outranks(actorRole, member.Role) {
+		return apperrors.Forbidden("you cannot remove a member with the %s role", member.Role)
+	}
+
+	err = s.Repo.RemoveMember(context.TODO(), member.ID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrMemberNotFound
+	}
+	return err
+}
+
+// Invite emails an invitation to join the organization, replacing any pending
+// invitation for the same address. Only the owner can invite admins.
+func (s *OrganizationService) Invite(orgID, inviterID, inviterRole string, invitation *models.Invitation) error {
+	org, err := s.GetOrganization(orgID)
+	if err != nil {
+		return err
+	}
+	inviterObjID, err := primitive.ObjectIDFromHex(inviterID)
+	if err != nil {
+		return apperrors.InvalidID("invalid user ID format")
+	}
+	if !outranks(inviterRole, invitation.Role) {
+		return apperrors.Forbidden("you cannot invite members with the %s role", invitation.Role)
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	invitation.ID = primitive.NewObjectID()
+	invitation.OrgID = org.ID
+	invitation.Email = normalizeEmail(invitation.Email)
+	invitation.TokenHash = utils.HashToken(token)
+	invitation.InvitedBy = inviterObjID
+	invitation.ExpiresAt = now.Add(InvitationTTL)
+	invitation.AcceptedAt = nil
+	invitation.AcceptedBy = primitive.NilObjectID
+	invitation.CreatedAt = now
+
+	if err := s.Repo.CreateInvitation(context.TODO(), invitation); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/accept-invitation?token=%s", s.FrontendURL, url.QueryEscape(token))
+	return s.Mailer.Send(mailer.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("Join %s on the job portal", org.Name),
+		Body: fmt.Sprintf("Hi,\n\nYou have been invited to join %s as %s. Sign in with this email address and open the link below to accept. It expires in %s.\n\n%s",
+			org.Name, invitation.Role, InvitationTTL, link),
+	})
+}
+
+// ListInvitations retrieves the invitations of an organization that can still be accepted
+func (s *OrganizationService) ListInvitations(orgID string) ([]models.Invitation, error) {
+	objID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return nil, apperrors.InvalidID("invalid organization ID format")
+	}
+
+	return s.Repo.ListPendingInvitations(context.TODO(), objID, time.Now())
+}
+
+// RevokeInvitation deletes a pending invitation so its link stops working
+func (s *OrganizationService) RevokeInvitation(orgID, invitationID string) error {
+	orgObjID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return apperrors.InvalidID("invalid organization ID format")
+	}
+	objID, err := primitive.ObjectIDFromHex(invitationID)
+	if err != nil {
+		return apperrors.InvalidID("invalid invitation ID format")
+	}
+
+	err = s.Repo.RevokeInvitation(context.TODO(), orgObjID, objID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrInvitationNotFound
+	}
+	return err
+}
+
+// AcceptInvitation makes the user a member of the organization the token invites
+// to. The invitation must have been sent to the user's email address.
+func (s *OrganizationService) AcceptInvitation(token, userID, email string) (*models.Membership, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, apperrors.InvalidID("invalid user ID format")
+	}
+
+	invitation, err := s.Repo.FindPendingInvitation(context.TODO(), utils.HashToken(token), time.Now())
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrInvalidInvitation
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(invitation.Email, email) {
+		return nil, ErrInvitationForOther
+	}
+
+	role, err := s.MemberRole(invitation.OrgID.Hex(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if role != "" {
+		return nil, ErrAlreadyMember
+	}
+
+	// Only one acceptance wins when the token is used concurrently
+	err = s.Repo.AcceptInvitation(context.TODO(), invitation.ID, userObjID, time.Now())
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrInvalidInvitation
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.addMember(invitation.OrgID, userObjID, invitation.Role); err != nil {
+		return nil, err
+	}
+	return s.getMember(invitation.OrgID.Hex(), userID)
+}
+
+// addMember inserts a membership; the unique index on org_id and user_id rejects duplicates
+func (s *OrganizationService) addMember(orgID, userID primitive.ObjectID, role string) error {
+	now := time.Now()
+	membership := models.Membership{
+		ID:        primitive.NewObjectID(),
+		OrgID:     orgID,
+		UserID:    userID,
+		Role:      role,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err := s.Repo.AddMember(context.TODO(), &membership)
+	if errors.Is(err, repositories.ErrDuplicate) {
+		return ErrAlreadyMember
+	}
+	return err
+}
+
+func (s *OrganizationService) getMember(orgID, userID string) (*models.Membership, error) {
+	orgObjID, err := primitive.ObjectIDFromHex(orgID)
+	if err != nil {
+		return nil, apperrors.InvalidID("invalid organization ID format")
+	}
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, apperrors.InvalidID("invalid user ID format")
+	}
+
+	membership, err := s.Repo.FindMember(context.TODO(), orgObjID, userObjID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, ErrMemberNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return membership, nil
+}
+
+func (s *OrganizationService) setRole(membership *models.Membership, role string) error {
+	membership.Role = role
+	membership.UpdatedAt = time.Now()
+	err := s.Repo.SetMemberRole(context.TODO(), membership.ID, role, membership.UpdatedAt)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return ErrMemberNotFound
+	}
+	return err
+}
+
+// outranks reports whether an organization role is above another: the owner is
+// above admins, and admins are above members
+func outranks(role, other string) bool {
+	rank := map[string]int{models.OrgRoleOwner: 3, models.OrgRoleAdmin: 2, models.OrgRoleMember: 1}
+	return rank[role] > rank[other]
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"job-portal/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestOrganizationInvitations(t *testing.T) {
+	f := newFixture(t)
+	s, mail := f.orgs, f.mail
+	owner, invitee, other := primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+
+	org := &models.Organization{Name: "Acme Recruiting"}
+	if err := s.CreateOrganization(org, owner); err != nil {
+		t.Fatal(err)
+	}
+	if role, _ := s.MemberRole(org.ID.Hex(), owner); role != models.OrgRoleOwner {
+		t.Fatalf("the creator's role is %q, want owner", role)
+	}
+
+	// A newer invitation to the same address replaces the pending one
+	if err := s.Invite(org.ID.Hex(), owner, models.OrgRoleOwner, &models.Invitation{Email: "Dev@Example.com", Role: models.OrgRoleMember}); err != nil {
+		t.Fatal(err)
+	}
+	replaced := mail.invitationToken(t)
+	if err := s.Invite(org.ID.Hex(), owner, models.OrgRoleOwner, &models.Invitation{Email: "dev@example.com", Role: models.OrgRoleAdmin}); err != nil {
+		t.Fatal(err)
+	}
+	token := mail.invitationToken(t)
+	pending, err := s.ListInvitations(org.ID.Hex())
+	if err != nil || len(pending) != 1 || pending[0].Role != models.OrgRoleAdmin {
+		t.Fatalf("pending invitations = %+v, %v", pending, err)
+	}
+
+	if _, err := s.AcceptInvitation(replaced, invitee, "dev@example.com"); !errors.Is(err, ErrInvalidInvitation) {
+		t.Errorf("a replaced invitation gave %v, want ErrInvalidInvitation", err)
+	}
+	if _, err := s.AcceptInvitation(token, other, "someone@example.com"); !errors.Is(err, ErrInvitationForOther) {
+		t.Errorf("another address gave %v, want ErrInvitationForOther", err)
+	}
+	membership, err := s.AcceptInvitation(token, invitee, "DEV@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if membership.Role != models.OrgRoleAdmin {
+		t.Errorf("the invitee joined as %q, want admin", membership.Role)
+	}
+	if _, err := s.AcceptInvitation(token, other, "dev@example.com"); !errors.Is(err, ErrInvalidInvitation) {
+		t.Errorf("reusing an invitation gave %v, want ErrInvalidInvitation", err)
+	}
+
+	// Admins cannot invite admins
+	if err := s.Invite(org.ID.Hex(), invitee, models.OrgRoleAdmin, &models.Invitation{Email: "boss@example.com", Role: models.OrgRoleAdmin}); err == nil {
+		t.Error("an admin invited an admin")
+	}
+
+	orgs, err := s.ListUserOrganizations(invitee)
+	if err != nil || len(orgs) != 1 || orgs[0].Name != "Acme Recruiting" || orgs[0].Role != models.OrgRoleAdmin {
+		t.Errorf("the invitee's organizations = %+v, %v", orgs, err)
+	}
+}
+
+func TestOrganizationMembers(t *testing.T) {
+	ctx := context.Background()
+	s := newFixture(t).orgs
+	owner, admin, member := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	org := &models.Organization{Name: "Acme"}
+	if err := s.CreateOrganization(org, owner.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	for user, role := range map[primitive.ObjectID]string{admin: models.OrgRoleAdmin, member: models.OrgRoleMember} {
+		if err := s.addMember(org.ID, user, role); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.addMember(org.ID, member, models.OrgRoleMember); !errors.Is(err, ErrAlreadyMember) {
+		t.Errorf("adding a member twice gave %v, want ErrAlreadyMember", err)
+	}
+
+	if err := s.RemoveMember(org.ID.Hex(), admin.Hex(), models.OrgRoleAdmin, owner.Hex()); !errors.Is(err, ErrCannotRemoveOwner) {
+		t.Errorf("removing the owner gave %v, want ErrCannotRemoveOwner", err)
+	}
+	if _, err := s.ChangeMemberRole(org.ID.Hex(), admin.Hex(), member.Hex(), models.OrgRoleAdmin); !errors.Is(err, ErrOwnerOnly) {
+		t.Errorf("an admin changing roles gave %v, want ErrOwnerOnly", err)
+	}
+
+	// Transferring the ownership makes the previous owner an admin
+	if _, err := s.ChangeMemberRole(org.ID.Hex(), owner.Hex(), admin.Hex(), models.OrgRoleOwner); err != nil {
+		t.Fatal(err)
+	}
+	members, err := s.ListMembers(org.ID.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	roles := map[primitive.ObjectID]string{}
+	for _, m := range members {
+		roles[m.UserID] = m.Role
+	}
+	if roles[owner] != models.OrgRoleAdmin || roles[admin] != models.OrgRoleOwner || roles[member] != models.OrgRoleMember {
+		t.Errorf("roles after the transfer = %v", roles)
+	}
+
+	if err := s.RemoveMember(org.ID.Hex(), owner.Hex(), models.OrgRoleAdmin, member.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if role, _ := s.MemberRole(org.ID.Hex(), member.Hex()); role != "" {
+		t.Errorf("a removed member still has the %q role", role)
+	}
+
+	// An organization with jobs cannot be deleted
+	if err := s.Jobs.Create(ctx, &models.Job{ID: primitive.NewObjectID(), Title: "Engineer", OrgID: org.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteOrganization(org.ID.Hex()); !errors.Is(err, ErrOrganizationHasJobs) {
+		t.Errorf("deleting an organization with jobs gave %v, want ErrOrganizationHasJobs", err)
+	}
+}
+
+func TestInvitedMemberManagesOrgJobs(t *testing.T) {
+	f := newFixture(t)
+	orgs, jobs, applications, mail := f.orgs, f.jobs, f.applications, f.mail
+	recruiter, invitee, outsider, candidate := primitive.NewObjectID(), primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()
+
+	org := &models.Organization{Name: "Acme"}
+	if err := orgs.CreateOrganization(org, recruiter.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	company := f.company(t, recruiter, org.ID)
+	job := &models.Job{Title: "Engineer", Description: "Builds things", Location: "Dhaka", CompanyID: company.ID}
+	if err := jobs.CreateJob(job, recruiter.Hex(), models.RoleRecruiter, org.ID.Hex()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := orgs.Invite(org.ID.Hex(), recruiter.Hex(), models.OrgRoleOwner, &models.Invitation{Email: "dev@example.com", Role: models.OrgRoleMember}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := orgs.AcceptInvitation(mail.invitationToken(t), invitee, "dev@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The invitee keeps the user role; membership alone lets them manage the organization's job
+	if _, err := jobs.UpdateJob(job.ID.Hex(), outsider, models.RoleUser, nil, map[string]interface{}{"title": "Hijacked"}, noValidation); !errors.Is(err, ErrNotJobOwner) {
+		t.Errorf("an outsider updating the job gave %v, want ErrNotJobOwner", err)
+	}
+	updated, err := jobs.UpdateJob(job.ID.Hex(), invitee, models.RoleUser, nil, map[string]interface{}{"title": "Senior Engineer"}, noValidation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Title != "Senior Engineer" {
+		t.Errorf("the title is %q after the update", updated.Title)
+	}
+	if _, err := jobs.PublishJob(job.ID.Hex(), invitee, models.RoleUser, time.Time{}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := applications.Apply(job.ID.Hex(), candidate, &models.Application{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := applications.ListByJob(job.ID.Hex(), outsider, models.RoleUser); !errors.Is(err, ErrCannotReview) {
+		t.Errorf("an outsider reviewing gave %v, want ErrCannotReview", err)
+	}
+	reviewed, err := applications.ListByJob(job.ID.Hex(), invitee, models.RoleUser)
+	if err != nil || len(reviewed) != 1 {
+		t.Errorf("the invitee's review = %+v, %v", reviewed, err)
+	}
+}
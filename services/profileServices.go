@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"job-portal/apperrors"
+	"job-portal/models"
+	"job-portal/repositories"
+	"job-portal/storage"
+	"job-portal/utils"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrResumeNotFound is returned for a user who has not uploaded a resume
+var ErrResumeNotFound = apperrors.NotFound("no resume has been uploaded")
+
+// MaxResumeSize caps the size of an uploaded resume. Uploads are also bound by BODY_LIMIT.
+const MaxResumeSize = 2 << 20
+
+// resumeFormat is an accepted resume file type
+type resumeFormat struct {
+	contentType string // Stored and served content type
+	sniffed     string // What http.DetectContentType reports for such a file
+}
+
+// resumeFormats maps the accepted file extensions to their formats. DOCX files
+// are ZIP archives and DOC files have no signature DetectContentType knows.
+var resumeFormats = map[string]resumeFormat{
+	".pdf":  {contentType: "application/pdf", sniffed: "application/pdf"},
+	".docx": {contentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", sniffed: "application/zip"},
+	".doc":  {contentType: "application/msword", sniffed: "application/octet-stream"},
+}
+
+type ProfileService struct {
+	Repo  repositories.ProfileRepository
+	Blobs storage.BlobStore
+}
+
+// NewProfileService creates a new instance of ProfileService
+func NewProfileService(repo repositories.ProfileRepository, blobs storage.BlobStore) *ProfileService {
+	return &ProfileService{Repo: repo, Blobs: blobs}
+}
+
+// GetProfile retrieves the user's profile. A user who never saved one gets an empty profile.
+func (s *ProfileService) GetProfile(userID string) (*models.Profile, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, apperrors.InvalidID("invalid user ID format")
+	}
+
+	profile, err := s.Repo.FindByUserID(context.TODO(), userObjID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return &models.Profile{UserID: userObjID, Skills: []string{}, Experience: []models.ExperienceEntry{}, Education: []models.EducationEntry{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// UpdateProfile replaces the editable parts of the user's profile, creating it
+// on the first save. The resume is kept; it only changes through UploadResume.
+func (s *ProfileService) UpdateProfile(userID string, update *models.Profile) (*models.Profile, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, apperrors.InvalidID("invalid user ID format")
+	}
+
+	problems := map[string]string{}
+	for i, entry := range update.Experience {
+		if entry.EndDate != nil && entry.EndDate.Before(entry.StartDate) {
+			problems[fmt.Sprintf("experience[%d].end_date", i)] = "must not be before start_date"
+		}
+	}
+	if len(problems) > 0 {
+		return nil, apperrors.Validation("invalid profile", problems)
+	}
+
+	update.Preferences.JobTypes = nonNil(update.Preferences.JobTypes)
+	update.Preferences.WorkLocations = nonNil(update.Preferences.WorkLocations)
+	fields := map[string]interface{}{
+		"headline":    strings.TrimSpace(update.Headline),
+		"skills":      nonNil(update.Skills),
+		"experience":  nonNil(update.Experience),
+		"education":   nonNil(update.Education),
+		"location":    strings.TrimSpace(update.Location),
+		"preferences": update.Preferences,
+	}
+	return s.save(userObjID, fields)
+}
+
+// UploadResume stores a PDF, DOC or DOCX resume for the user and attaches it to
+// their profile, replacing the previous one
+func (s *ProfileService) UploadResume(userID, fileName string, content io.Reader) (*models.Profile, error) {
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, apperrors.InvalidID("invalid user ID format")
+	}
+
+	ext := strings.ToLower(filepath.Ext(fileName))
+	format, ok := resumeFormats[ext]
+	if !ok {
+		return nil, apperrors.Validation("unsupported resume format", map[string]string{"resume": "must be a .pdf, .doc or .docx file"})
+	}
+
+	// Check that the content matches the extension before storing anything
+	head := make([]byte, 512)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	head = head[:n]
+	if n == 0 || http.DetectContentType(head) != format.sniffed {
+		return nil, apperrors.Validation("invalid resume file", map[string]string{"resume": fmt.Sprintf("is not a valid %s file", ext)})
+	}
+
+	suffix, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("resumes/%s/%s%s", userObjID.Hex(), suffix, ext)
+
+	// Count what is actually stored rather than trusting the size the client
+	// reported; reading one byte past the cap tells an oversized file apart
+	counted := &countingReader{r: io.LimitReader(io.MultiReader(bytes.NewReader(head), content), MaxResumeSize+1)}
+	if err := s.Blobs.Put(context.TODO(), key, counted); err != nil {
+		return nil, err
+	}
+	if counted.n > MaxResumeSize {
+		s.deleteBlob(key)
+		return nil, apperrors.Validation("resume too large", map[string]string{"resume": fmt.Sprintf("must be at most %d MB", MaxResumeSize>>20)})
+	}
+
+	previous, err := s.GetProfile(userID)
+	if err != nil {
+		s.deleteBlob(key)
+		return nil, err
+	}
+	resume := models.Resume{
+		Key:         key,
+		FileName:    filepath.Base(fileName),
+		ContentType: format.contentType,
+		Size:        counted.n,
+		UploadedAt:  time.Now(),
+	}
+	profile, err := s.save(userObjID, map[string]interface{}{"resume": resume})
+	if err != nil {
+		s.deleteBlob(key) // Do not leave an unreferenced file behind
+		return nil, err
+	}
+
+	if previous.Resume != nil {
+		s.deleteBlob(previous.Resume.Key)
+	}
+	return profile, nil
+}
+
+// OpenResume opens the user's resume; the caller closes the returned content
+func (s *ProfileService) OpenResume(userID string) (*models.Resume, io.ReadCloser, error) {
+	profile, err := s.GetProfile(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if profile.Resume == nil {
+		return nil, nil, ErrResumeNotFound
+	}
+	content, err := s.Blobs.Get(context.TODO(), profile.Resume.Key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrResumeNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return profile.Resume, content, nil
+}
+
+// save sets fields on the user's profile, creating the profile if needed, and returns it
+func (s *ProfileService) save(userID primitive.ObjectID, fields map[string]interface{}) (*models.Profile, error) {
+	fields["updated_at"] = time.Now()
+	return s.Repo.Upsert(context.TODO(), userID, fields)
+}
+
+// deleteBlob removes a stored file, logging rather than failing the request when it cannot
+func (s *ProfileService) deleteBlob(key string) {
+	if err := s.Blobs.Delete(context.TODO(), key); err != nil {
+		log.Printf("Could not delete resume %s: %v", key, err)
+	}
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// nonNil turns a nil slice into an empty one, so it is stored and returned as []
+func nonNil[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+	return values
+}
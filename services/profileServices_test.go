@@ -0,0 +1,89 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"job-portal/storage"
+	"path/filepath"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUploadResume(t *testing.T) {
+	s := newFixture(t).profiles
+	userID := primitive.NewObjectID().Hex()
+
+	first, err := s.UploadResume(userID, "cv.pdf", bytes.NewReader(pdf(1000)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Resume == nil || first.Resume.Size != 1000 || first.Resume.ContentType != "application/pdf" {
+		t.Fatalf("resume = %+v", first.Resume)
+	}
+	if first.Skills == nil || first.Experience == nil || first.Education == nil {
+		t.Error("a profile created by an upload should have empty lists")
+	}
+
+	second, err := s.UploadResume(userID, "new-cv.pdf", bytes.NewReader(pdf(MaxResumeSize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Resume.Size != MaxResumeSize || second.Resume.FileName != "new-cv.pdf" {
+		t.Fatalf("resume = %+v", second.Resume)
+	}
+	if _, err := s.Blobs.Get(context.Background(), first.Resume.Key); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("the replaced resume is still stored: %v", err)
+	}
+	stored, err := s.Blobs.Get(context.Background(), second.Resume.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(stored)
+	stored.Close()
+	if len(data) != MaxResumeSize {
+		t.Errorf("stored %d bytes, want %d", len(data), MaxResumeSize)
+	}
+}
+
+func TestUploadResumeRejectsInvalidFiles(t *testing.T) {
+	f := newFixture(t)
+	s := f.profiles
+	userID := primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name     string
+		fileName string
+		content  []byte
+	}{
+		{"too large", "cv.pdf", pdf(MaxResumeSize + 1)},
+		{"unsupported extension", "cv.txt", []byte("plain text")},
+		{"content does not match the extension", "cv.pdf", []byte("<html><body>not a pdf</body></html>")},
+		{"empty file", "cv.docx", nil},
+	}
+	for _, tt := range tests {
+		if _, err := s.UploadResume(userID, tt.fileName, bytes.NewReader(tt.content)); err == nil {
+			t.Errorf("%s: the upload was accepted", tt.name)
+		}
+	}
+
+	profile, err := s.GetProfile(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Resume != nil {
+		t.Errorf("a rejected upload was attached: %+v", profile.Resume)
+	}
+	err = filepath.WalkDir(f.uploads, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			t.Errorf("a rejected upload was left in storage: %s", path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
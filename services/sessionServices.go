@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"job-portal/apperrors"
+	"job-portal/models"
+	"job-portal/token"
+	"job-portal/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrInvalidRefreshToken = apperrors.Unauthorized("invalid or expired refresh token")
+	ErrRefreshTokenReused  = apperrors.Unauthorized("refresh token reuse detected, session revoked")
+)
+
+// RevokedSessionRetention is how long revoked sessions are kept before PurgeStale deletes them
+const RevokedSessionRetention = 7 * 24 * time.Hour
+
+type SessionService struct {
+	Collection *mongo.Collection
+}
+
+// NewSessionService creates a new instance of SessionService
+func NewSessionService(collection *mongo.Collection) *SessionService {
+	return &SessionService{Collection: collection}
+}
+
+// Create starts a new session for the user and returns its first refresh token
+func (s *SessionService) Create(userID primitive.ObjectID, userAgent, ip string) (string, *models.Session, error) {
+	refreshToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		ID:               primitive.NewObjectID(),
+		UserID:           userID,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		PreviousHashes:   []string{},
+		UserAgent:        userAgent,
+		IP:               ip,
+		ExpiresAt:        now.Add(token.RefreshTokenTTL),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	if _, err := s.Collection.InsertOne(context.TODO(), session); err != nil {
+		return "", nil, err
+	}
+	return refreshToken, session, nil
+}
+
+// Rotate exchanges a refresh token for a new one. Presenting a token that has
+// already been rotated revokes the whole session, since it means the token leaked.
+func (s *SessionService) Rotate(refreshToken string) (string, *models.Session, error) {
+	hash := utils.HashToken(refreshToken)
+
+	var session models.Session
+	err := s.Collection.FindOne(context.TODO(), bson.M{"refresh_token_hash": hash}).Decode(&session)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// Not the current token: check whether it is an old one being replayed
+		err = s.Collection.FindOne(context.TODO(), bson.M{"previous_hashes": hash}).Decode(&session)
+		if err == nil {
+			if err := s.Revoke(session.ID.Hex()); err != nil {
+				return "", nil, err
+			}
+			return "", nil, ErrRefreshTokenReused
+		}
+		return "", nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	if session.RevokedAt != nil || now.After(session.ExpiresAt) {
+		return "", nil, ErrInvalidRefreshToken
+	}
+
+	newToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+	newHash := utils.HashToken(newToken)
+
+	// Match on the old hash so two concurrent refreshes cannot both succeed
+	filter := bson.M{"_id": session.ID, "refresh_token_hash": hash, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{
+		"$set":  bson.M{"refresh_token_hash": newHash, "expires_at": now.Add(token.RefreshTokenTTL), "updated_at": now},
+		"$push": bson.M{"previous_hashes": hash},
+	}
+	result, err := s.Collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return "", nil, err
+	}
+	if result.MatchedCount == 0 {
+		return "", nil, ErrInvalidRefreshToken
+	}
+
+	session.RefreshTokenHash = newHash
+	session.ExpiresAt = now.Add(token.RefreshTokenTTL)
+	session.UpdatedAt = now
+	return newToken, &session, nil
+}
+
+// Revoke ends a single session
+func (s *SessionService) Revoke(sessionID string) error {
+	objID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return apperrors.InvalidID("invalid session ID format")
+	}
+
+	now := time.Now()
+	_, err = s.Collection.UpdateOne(context.TODO(),
+		bson.M{"_id": objID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": now, "updated_at": now}},
+	)
+	return err
+}
+
+// RevokeAllForUser ends every session of the user
+func (s *SessionService) RevokeAllForUser(userID string) error {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return apperrors.InvalidID("invalid user ID format")
+	}
+
+	now := time.Now()
+	_, err = s.Collection.UpdateMany(context.TODO(),
+		bson.M{"user_id": objID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": now, "updated_at": now}},
+	)
+	return err
+}
+
+// PurgeStale deletes sessions that have expired, and revoked sessions once
+// their revocation is older than retention
+func (s *SessionService) PurgeStale(ctx context.Context, retention time.Duration) (int64, error) {
+	now := time.Now()
+	result, err := s.Collection.DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"expires_at": bson.M{"$lte": now}},
+		bson.M{"revoked_at": bson.M{"$lte": now.Add(-retention)}},
+	}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// IsActive reports whether the session exists, has not been revoked and has not expired
+func (s *SessionService) IsActive(sessionID string) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(sessionID)
+	if err != nil {
+		return false, nil
+	}
+
+	count, err := s.Collection.CountDocuments(context.TODO(), bson.M{
+		"_id":        objID,
+		"revoked_at": bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"job-portal/apperrors"
+	"job-portal/models"
+	"job-portal/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrInvalidToken is returned for one-time tokens that are unknown, expired or already used
+var ErrInvalidToken = apperrors.BadRequest("invalid or expired token")
+
+type TokenService struct {
+	Collection *mongo.Collection
+}
+
+// NewTokenService creates a new instance of TokenService
+func NewTokenService(collection *mongo.Collection) *TokenService {
+	return &TokenService{Collection: collection}
+}
+
+// Issue creates a single-use token for the user that expires after ttl.
+// Any earlier unused token for the same purpose is invalidated.
+func (s *TokenService) Issue(userID primitive.ObjectID, purpose string, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	_, err = s.Collection.UpdateMany(context.TODO(),
+		bson.M{"user_id": userID, "purpose": purpose, "used_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_at": now}},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	record := models.OneTimeToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if _, err := s.Collection.InsertOne(context.TODO(), record); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Consume marks a token as used and returns it. It fails if the token is unknown,
+// was issued for another purpose, has expired or has already been used.
+func (s *TokenService) Consume(token, purpose string) (*models.OneTimeToken, error) {
+	now := time.Now()
+	filter := bson.M{
+		"token_hash": utils.HashToken(token),
+		"purpose":    purpose,
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"used_at": now}}
+
+	var record models.OneTimeToken
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := s.Collection.FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// PurgeStale deletes tokens that have expired or have been used, which can never be consumed again
+func (s *TokenService) PurgeStale(ctx context.Context) (int64, error) {
+	result, err := s.Collection.DeleteMany(ctx, bson.M{"$or": bson.A{
+		bson.M{"expires_at": bson.M{"$lte": time.Now()}},
+		bson.M{"used_at": bson.M{"$exists": true}},
+	}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
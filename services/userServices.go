@@ -3,35 +3,75 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"job-portal/apperrors"
+	"job-portal/mailer"
 	"job-portal/models"
-	"job-portal/utils"
-
+	"job-portal/policy"
+	"job-portal/repositories"
+	"job-portal/token"
+	"log"
+	"net/url"
+	"strings"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	PasswordResetTTL     = time.Hour      // How long a password reset link stays valid
+	EmailVerificationTTL = 48 * time.Hour // How long an email verification link stays valid
+)
+
+// ErrAlreadyVerified is returned when a verification email is requested for a verified account
+var ErrAlreadyVerified = apperrors.Conflict("email address is already verified")
+
+// ErrInvalidCredentials is returned for an unknown email or a wrong password, without saying which
+var ErrInvalidCredentials = apperrors.Unauthorized("invalid email or password")
+
+// dummyPasswordHash is compared against when no account has the email, so an
+// unknown email takes as long to reject as a wrong password
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not the password of any account"), bcrypt.DefaultCost)
+
+// normalizeEmail returns the form emails are stored and looked up in, so that
+// Foo@Example.com and foo@example.com are the same account
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 type UserService struct {
-	Collection *mongo.Collection
+	Repo        repositories.UserRepository
+	Sessions    *SessionService
+	Tokens      *TokenService
+	JWT         *token.Manager
+	Mailer      mailer.Mailer
+	FrontendURL string // Base URL of the web app, used to build links in emails
+}
+
+// TokenPair is the access and refresh token issued on login and refresh
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
 }
 
-func NewUserService(collection *mongo.Collection) *UserService {
-	return &UserService{Collection: collection}
+func NewUserService(repo repositories.UserRepository, sessions *SessionService, tokens *TokenService, jwt *token.Manager, mail mailer.Mailer, frontendURL string) *UserService {
+	return &UserService{Repo: repo, Sessions: sessions, Tokens: tokens, JWT: jwt, Mailer: mail, FrontendURL: frontendURL}
 }
 
 func (s *UserService) Register(user *models.User) error {
+	user.Email = normalizeEmail(user.Email)
+
 	// Check if email already exists
-	var existingUser models.User
-	err := s.Collection.FindOne(context.TODO(), bson.M{"email": user.Email}).Decode(&existingUser)
+	_, err := s.Repo.FindByEmail(context.TODO(), user.Email)
 	if err == nil {
-		return errors.New("email already in use")
+		return apperrors.Conflict("email already in use")
 	}
-	if user.Role == "" {
-		user.Role = "user"
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return err
 	}
+	// Everyone signs up as a candidate; only SetRole grants more
+	user.Role = models.RoleUser
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -39,37 +79,232 @@ func (s *UserService) Register(user *models.User) error {
 	}
 	user.Password = string(hashedPassword)
 
+	// Every new account starts unverified, whatever the request said
+	user.EmailVerified = false
+	user.VerifiedAt = nil
+
 	// Set timestamps
 	user.ID = primitive.NewObjectID()
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
 
-	// Insert user into database
-	_, err = s.Collection.InsertOne(context.TODO(), user)
-	return err
+	// Insert user into database. The unique index on email catches a concurrent
+	// registration that slipped past the check above.
+	err = s.Repo.Create(context.TODO(), user)
+	if errors.Is(err, repositories.ErrDuplicate) {
+		return apperrors.Conflict("email already in use")
+	}
+	if err != nil {
+		return err
+	}
+
+	// The account exists now; a failed email can be retried through /verify-email/resend
+	if err := s.sendVerificationEmail(user); err != nil {
+		log.Printf("failed to send verification email to %s: %v", user.Email, err)
+	}
+	return nil
+}
+
+// ResendVerification sends a new verification link to the user
+func (s *UserService) ResendVerification(userID string) error {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return apperrors.InvalidID("invalid user ID format")
+	}
+
+	user, err := s.findByID(objID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return ErrAlreadyVerified
+	}
+	return s.sendVerificationEmail(user)
+}
+
+// VerifyEmail marks the account the verification token was issued for as verified
+func (s *UserService) VerifyEmail(token string) (*models.User, error) {
+	record, err := s.Tokens.Consume(token, models.TokenEmailVerification)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user, err := s.Repo.Update(context.TODO(), record.UserID, map[string]interface{}{
+		"email_verified": true,
+		"verified_at":    now,
+		"updated_at":     now,
+	})
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, apperrors.NotFound("user not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	user.Password = "" // Never send the hash back
+	return user, nil
 }
 
-// Authenticate authenticates a user by email and password, and generates a JWT token
-func (s *UserService) Authenticate(email, password string) (string, *models.User, error) {
-	var user models.User
+// findByID looks up a user, reporting a missing one as NotFound
+func (s *UserService) findByID(id primitive.ObjectID) (*models.User, error) {
+	user, err := s.Repo.FindByID(context.TODO(), id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, apperrors.NotFound("user not found")
+	}
+	return user, err
+}
 
+func (s *UserService) sendVerificationEmail(user *models.User) error {
+	token, err := s.Tokens.Issue(user.ID, models.TokenEmailVerification, EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.FrontendURL, url.QueryEscape(token))
+	return s.Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %s.\n\n%s",
+			user.Name, EmailVerificationTTL, link),
+	})
+}
+
+// Authenticate authenticates a user by email and password, starts a session and generates its tokens
+func (s *UserService) Authenticate(email, password, userAgent, ip string) (*TokenPair, *models.User, error) {
 	// Find user by email
-	err := s.Collection.FindOne(context.TODO(), bson.M{"email": email}).Decode(&user)
+	user, err := s.Repo.FindByEmail(context.TODO(), normalizeEmail(email))
+	if errors.Is(err, repositories.ErrNotFound) {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, nil, ErrInvalidCredentials
+	}
 	if err != nil {
-		return "", nil, errors.New(err.Error())
+		return nil, nil, err
 	}
 
 	// Compare password
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return "", nil, errors.New(err.Error())
+		return nil, nil, ErrInvalidCredentials
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateJWT(user)
+	// Start a session and generate its tokens
+	refreshToken, session, err := s.Sessions.Create(user.ID, userAgent, ip)
+	if err != nil {
+		return nil, nil, err
+	}
+	accessToken, err := s.JWT.Issue(*user, session.ID.Hex())
 	if err != nil {
-		return "", nil, errors.New("failed to generate token")
+		return nil, nil, errors.New("failed to generate token")
+	}
+
+	return &TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, user, nil
+}
+
+// Refresh rotates a refresh token and issues a new access token for its session
+func (s *UserService) Refresh(refreshToken string) (*TokenPair, *models.User, error) {
+	newRefreshToken, session, err := s.Sessions.Rotate(refreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Reload the user so role changes take effect on the next access token
+	user, err := s.findByID(session.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accessToken, err := s.JWT.Issue(*user, session.ID.Hex())
+	if err != nil {
+		return nil, nil, errors.New("failed to generate token")
+	}
+
+	return &TokenPair{AccessToken: accessToken, RefreshToken: newRefreshToken}, user, nil
+}
+
+// Logout revokes a single session
+func (s *UserService) Logout(sessionID string) error {
+	return s.Sessions.Revoke(sessionID)
+}
+
+// LogoutAll revokes every session of the user
+func (s *UserService) LogoutAll(userID string) error {
+	return s.Sessions.RevokeAllForUser(userID)
+}
+
+// SetRole gives a user another role. The user's sessions are revoked, since their
+// access tokens carry the old role, so the new one applies from the next login.
+func (s *UserService) SetRole(userID, role string) (*models.User, error) {
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, apperrors.InvalidID("invalid user ID format")
+	}
+	if len(policy.Permissions(role)) == 0 {
+		return nil, apperrors.Validation("unknown role", map[string]string{"role": "must be one of: user, recruiter, admin"})
+	}
+
+	user, err := s.Repo.Update(context.TODO(), objID, map[string]interface{}{"role": role, "updated_at": time.Now()})
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, apperrors.NotFound("user not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Sessions.RevokeAllForUser(userID); err != nil {
+		return nil, err
+	}
+	user.Password = "" // Never send the hash back
+	return user, nil
+}
+
+// ForgotPassword emails a single-use password reset link to the user with the given email.
+// Unknown emails are ignored so the endpoint cannot be used to discover accounts.
+func (s *UserService) ForgotPassword(email string) error {
+	user, err := s.Repo.FindByEmail(context.TODO(), normalizeEmail(email))
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := s.Tokens.Issue(user.ID, models.TokenPasswordReset, PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.FrontendURL, url.QueryEscape(token))
+	return s.Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s and can only be used once.\n\n%s\n\nIf you didn't ask for this, you can ignore this email.",
+			user.Name, PasswordResetTTL, link),
+	})
+}
+
+// ResetPassword sets a new password using a reset token and signs the user out everywhere
+func (s *UserService) ResetPassword(token, newPassword string) error {
+	record, err := s.Tokens.Consume(token, models.TokenPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	// Hash password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Repo.Update(context.TODO(), record.UserID, map[string]interface{}{
+		"password":   string(hashedPassword),
+		"updated_at": time.Now(),
+	})
+	if errors.Is(err, repositories.ErrNotFound) {
+		return apperrors.NotFound("user not found")
+	}
+	if err != nil {
+		return err
 	}
 
-	return token, &user, nil
+	// Existing sessions may belong to whoever knew the old password
+	return s.Sessions.RevokeAllForUser(record.UserID.Hex())
 }
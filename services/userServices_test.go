@@ -0,0 +1,32 @@
+package services
+
+import (
+	"errors"
+	"job-portal/repositories"
+	"testing"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"ann@example.com", "ann@example.com"},
+		{"Ann@Example.COM", "ann@example.com"},
+		{"  ann@example.com\n", "ann@example.com"},
+	}
+	for _, tt := range tests {
+		if got := normalizeEmail(tt.email); got != tt.want {
+			t.Errorf("normalizeEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestAuthenticateRejectsUnknownEmails(t *testing.T) {
+	s := &UserService{Repo: repositories.NewMemoryUserRepository()}
+
+	_, _, err := s.Authenticate("nobody@example.com", "password", "", "")
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("err = %v, want %v", err, ErrInvalidCredentials)
+	}
+}
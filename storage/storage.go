@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned for a key that holds no blob
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps uploaded files, such as resumes, under slash separated keys.
+// Implementations can store them on a local disk, like LocalStore, or in an
+// object store such as S3.
+type BlobStore interface {
+	// Put stores the content under key, replacing any earlier blob
+	Put(ctx context.Context, key string, content io.Reader) error
+	// Get opens the blob stored under key; the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob under key. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,101 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key is a JWT signing or verification key, identified by the kid header
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}      // nil for verification-only keys
+	verifyKey interface{}      // HMAC secret or public key
+	public    crypto.PublicKey // nil for HMAC keys, which are never published
+}
+
+// CanSign reports whether the key holds the private part needed to sign tokens
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// NewHMACKey creates an HS256 key from a shared secret. An empty kid is
+// derived from the secret so every replica computes the same one.
+func NewHMACKey(secret []byte, kid string) *Key {
+	if kid == "" {
+		sum := sha256.Sum256(secret)
+		kid = "hs256-" + hex.EncodeToString(sum[:8])
+	}
+	return &Key{ID: kid, Method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+}
+
+// ParsePrivateKeyPEM creates an RS256 or EdDSA signing key from a PEM encoded
+// RSA or Ed25519 private key. An empty kid defaults to the RFC 7638 thumbprint.
+func ParsePrivateKeyPEM(data []byte, kid string) (*Key, error) {
+	if private, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return newKey(kid, jwt.SigningMethodRS256, private, &private.PublicKey)
+	}
+	if private, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		ed, ok := private.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("unsupported EdDSA key type")
+		}
+		return newKey(kid, jwt.SigningMethodEdDSA, ed, ed.Public())
+	}
+	return nil, errors.New("private key must be a PEM encoded RSA or Ed25519 key")
+}
+
+// ParsePublicKeyPEM creates a verification-only key from a PEM encoded RSA or
+// Ed25519 public key. An empty kid defaults to the RFC 7638 thumbprint.
+func ParsePublicKeyPEM(data []byte, kid string) (*Key, error) {
+	if public, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return newKey(kid, jwt.SigningMethodRS256, nil, public)
+	}
+	if public, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return newKey(kid, jwt.SigningMethodEdDSA, nil, public)
+	}
+	return nil, errors.New("public key must be a PEM encoded RSA or Ed25519 key")
+}
+
+func newKey(kid string, method jwt.SigningMethod, private interface{}, public crypto.PublicKey) (*Key, error) {
+	if kid == "" {
+		thumbprint, err := Thumbprint(public)
+		if err != nil {
+			return nil, err
+		}
+		kid = thumbprint
+	}
+	key := &Key{ID: kid, Method: method, verifyKey: public, public: public}
+	if private != nil {
+		key.signKey = private
+	}
+	return key, nil
+}
+
+// Thumbprint returns the RFC 7638 JWK thumbprint of a public key
+func Thumbprint(public crypto.PublicKey) (string, error) {
+	var canonical string
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		canonical = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, encodeInt(big.NewInt(int64(k.E))), encodeInt(k.N))
+	case ed25519.PublicKey:
+		canonical = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, base64.RawURLEncoding.EncodeToString(k))
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", public)
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func encodeInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
@@ -0,0 +1,96 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"job-portal/models"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AccessTokenTTL  = 15 * time.Minute    // Access tokens are short-lived and renewed through /auth/refresh
+	RefreshTokenTTL = 30 * 24 * time.Hour // Refresh tokens keep a session alive for 30 days
+)
+
+// ErrUnknownKey is returned for tokens signed with a key that is not (or no longer) trusted
+var ErrUnknownKey = errors.New("token signed with an unknown key")
+
+// Claims represents the custom claims in the JWT
+type Claims struct {
+	UserID        string `json:"user_id"`
+	Email         string `json:"email"`
+	Role          string `json:"role"`
+	EmailVerified bool   `json:"email_verified"`
+	SessionID     string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// Manager signs access tokens with one key and verifies them against every
+// active key, so keys can be rotated without logging everyone out: add the new
+// key as the signing key and keep the old one as a verification key until the
+// tokens it signed have expired.
+type Manager struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+// NewManager creates a Manager that signs with signing and also accepts tokens signed by previous
+func NewManager(signing *Key, previous ...*Key) (*Manager, error) {
+	if signing == nil || !signing.CanSign() {
+		return nil, errors.New("a signing key with its private part is required")
+	}
+
+	m := &Manager{signing: signing, keys: map[string]*Key{signing.ID: signing}}
+	for _, key := range previous {
+		if _, exists := m.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key ID %q", key.ID)
+		}
+		m.keys[key.ID] = key
+	}
+	return m, nil
+}
+
+// Issue creates an access token for the user, bound to the given session
+func (m *Manager) Issue(user models.User, sessionID string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		UserID:        user.ID.Hex(),
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+		SessionID:     sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+		},
+	}
+
+	token := jwt.NewWithClaims(m.signing.Method, claims)
+	token.Header["kid"] = m.signing.ID
+	return token.SignedString(m.signing.signKey)
+}
+
+// Parse verifies a token against the active keys and returns its claims
+func (m *Manager) Parse(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		key := m.signing // Tokens issued before key IDs were introduced carry no kid
+		if kid, ok := token.Header["kid"].(string); ok {
+			if key, ok = m.keys[kid]; !ok {
+				return nil, ErrUnknownKey
+			}
+		}
+
+		// The algorithm is pinned by the key, never taken from the token
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.verifyKey, nil
+	}, jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,322 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"job-portal/models"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	testSecret    = "a-test-secret-that-is-at-least-32-chars"
+	oldTestSecret = "an-older-secret-that-is-32-chars-long"
+)
+
+var testUser = models.User{ID: primitive.NewObjectID(), Email: "dev@example.com", Role: models.RoleRecruiter}
+
+// testRSAKey generates a 2048-bit RSA key once; generating it is slow
+var testRSAKey = func() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// pemFile writes a PEM block of the given type to a file in dir and returns its path
+func pemFile(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func privatePEM(t *testing.T, private interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func publicPEM(t *testing.T, public interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// sign builds a token with the given method, kid ("" for none) and claims
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func validClaims() *Claims {
+	return &Claims{
+		UserID: testUser.ID.Hex(),
+		Role:   testUser.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+}
+
+func TestParse(t *testing.T) {
+	rsaKey, err := ParsePrivateKeyPEM(privatePEM(t, testRSAKey), "rsa-2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldHMAC := NewHMACKey([]byte(oldTestSecret), "old")
+	manager, err := NewManager(rsaKey, oldHMAC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPrivate, _ := ed25519.GenerateKey(rand.Reader)
+
+	expired := validClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	noExpiry := validClaims()
+	noExpiry.ExpiresAt = nil
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"issued by the manager", mustIssue(t, manager), true},
+		{"signed by a previous key", sign(t, jwt.SigningMethodHS256, "old", []byte(oldTestSecret), validClaims()), true},
+		{"without a kid, against the signing key", sign(t, jwt.SigningMethodRS256, "", testRSAKey, validClaims()), true},
+		{"unknown kid", sign(t, jwt.SigningMethodHS256, "retired", []byte(oldTestSecret), validClaims()), false},
+		{"wrong secret for the kid", sign(t, jwt.SigningMethodHS256, "old", []byte(testSecret), validClaims()), false},
+		{"another key under the signing kid", sign(t, jwt.SigningMethodEdDSA, "rsa-2024", otherPrivate, validClaims()), false},
+		// The public key is public, so an HS256 token keyed with it must not pass for the RSA key
+		{"HS256 under an RSA kid", sign(t, jwt.SigningMethodHS256, "rsa-2024", publicPEM(t, &testRSAKey.PublicKey), validClaims()), false},
+		{"RS256 under an HMAC kid", sign(t, jwt.SigningMethodRS256, "old", testRSAKey, validClaims()), false},
+		{"alg none", sign(t, jwt.SigningMethodNone, "rsa-2024", jwt.UnsafeAllowNoneSignatureType, validClaims()), false},
+		{"alg none without a kid", sign(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, validClaims()), false},
+		{"expired", sign(t, jwt.SigningMethodRS256, "rsa-2024", testRSAKey, expired), false},
+		{"without an expiry", sign(t, jwt.SigningMethodRS256, "rsa-2024", testRSAKey, noExpiry), false},
+		{"garbage", "not.a.token", false},
+	}
+
+	for _, tt := range tests {
+		claims, err := manager.Parse(tt.token)
+		if tt.valid && (err != nil || claims.UserID != testUser.ID.Hex()) {
+			t.Errorf("%s: Parse = %+v, %v; want the user's claims", tt.name, claims, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: Parse accepted the token", tt.name)
+		}
+	}
+
+	if _, err := manager.Parse(sign(t, jwt.SigningMethodHS256, "retired", []byte(oldTestSecret), validClaims())); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("an unknown kid gave %v, want ErrUnknownKey", err)
+	}
+}
+
+func mustIssue(t *testing.T, m *Manager) string {
+	t.Helper()
+	token, err := m.Issue(testUser, "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRotation(t *testing.T) {
+	before, err := NewManager(NewHMACKey([]byte(oldTestSecret), ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued := mustIssue(t, before)
+
+	// The new secret signs; the old one stays as a verification key
+	after, err := NewManager(NewHMACKey([]byte(testSecret), ""), NewHMACKey([]byte(oldTestSecret), ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := after.Parse(issued)
+	if err != nil || claims.SessionID != "session-1" {
+		t.Fatalf("a token of the previous key gave %+v, %v", claims, err)
+	}
+	if _, err := before.Parse(mustIssue(t, after)); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("the old manager verified a token of the new key: %v", err)
+	}
+
+	// Once the old key is dropped, its tokens stop verifying
+	dropped, err := NewManager(NewHMACKey([]byte(testSecret), ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dropped.Parse(issued); err == nil {
+		t.Error("a token of a dropped key verified")
+	}
+}
+
+func TestNewManager(t *testing.T) {
+	public, err := ParsePublicKeyPEM(publicPEM(t, &testRSAKey.PublicKey), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewManager(public); err == nil {
+		t.Error("a verification-only key was accepted as the signing key")
+	}
+	if _, err := NewManager(nil); err == nil {
+		t.Error("a nil signing key was accepted")
+	}
+	if _, err := NewManager(NewHMACKey([]byte(testSecret), "same"), NewHMACKey([]byte(oldTestSecret), "same")); err == nil {
+		t.Error("two keys with the same kid were accepted")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	_, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey, err := ParsePrivateKeyPEM(privatePEM(t, edPrivate), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := ParsePublicKeyPEM(publicPEM(t, &testRSAKey.PublicKey), "rsa-old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager, err := NewManager(edKey, rsaKey, NewHMACKey([]byte(oldTestSecret), "hmac-old"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := manager.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS has %d keys, want the Ed25519 and RSA keys without the HMAC secret: %+v", len(set.Keys), set.Keys)
+	}
+	byKid := map[string]JWK{}
+	for i, jwk := range set.Keys {
+		if i > 0 && set.Keys[i-1].Kid > jwk.Kid {
+			t.Errorf("keys are not sorted by kid: %q before %q", set.Keys[i-1].Kid, jwk.Kid)
+		}
+		byKid[jwk.Kid] = jwk
+	}
+
+	ed, ok := byKid[edKey.ID]
+	if !ok || ed.Kty != "OKP" || ed.Crv != "Ed25519" || ed.Alg != EdDSA || ed.Use != "sig" {
+		t.Errorf("Ed25519 JWK = %+v", ed)
+	}
+	if x, _ := base64.RawURLEncoding.DecodeString(ed.X); !edPrivate.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(x)) {
+		t.Error("the Ed25519 JWK does not hold the public key")
+	}
+	if thumbprint, _ := Thumbprint(edPrivate.Public()); edKey.ID != thumbprint {
+		t.Errorf("the derived kid %q is not the thumbprint %q", edKey.ID, thumbprint)
+	}
+
+	rsaJWK := byKid["rsa-old"]
+	n, _ := base64.RawURLEncoding.DecodeString(rsaJWK.N)
+	e, _ := base64.RawURLEncoding.DecodeString(rsaJWK.E)
+	if rsaJWK.Kty != "RSA" || rsaJWK.Alg != RS256 || new(big.Int).SetBytes(n).Cmp(testRSAKey.N) != 0 || new(big.Int).SetBytes(e).Int64() != int64(testRSAKey.E) {
+		t.Errorf("RSA JWK = %+v", rsaJWK)
+	}
+	if _, ok := byKid["hmac-old"]; ok {
+		t.Error("the HMAC secret was published")
+	}
+}
+
+func TestSplitKeyID(t *testing.T) {
+	tests := []struct {
+		entry, kid, value string
+	}{
+		{"secret", "", "secret"},
+		{"2024-01=secret", "2024-01", "secret"},
+		{"key.v1=/etc/keys/old.pem", "key.v1", "/etc/keys/old.pem"},
+		{"=c2VjcmV0==", "", "c2VjcmV0=="},      // An empty kid keeps a value containing "="
+		{"c2VjcmV0==", "c2VjcmV0", "="},        // Ambiguous, hence "=value"; LoadManager then rejects the short secret
+		{"a b=secret", "", "a b=secret"},       // Spaces are not allowed in a kid
+		{"/keys/a=b.pem", "", "/keys/a=b.pem"}, // Nor are slashes
+		{"kid=a=b", "kid", "a=b"},
+	}
+
+	for _, tt := range tests {
+		if kid, value := SplitKeyID(tt.entry); kid != tt.kid || value != tt.value {
+			t.Errorf("SplitKeyID(%q) = %q, %q; want %q, %q", tt.entry, kid, value, tt.kid, tt.value)
+		}
+	}
+}
+
+func TestLoadManager(t *testing.T) {
+	dir := t.TempDir()
+	private := filepath.Join(dir, "signing.pem")
+	if err := os.WriteFile(private, privatePEM(t, testRSAKey), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	oldRSA, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&oldRSA.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := pemFile(t, dir, "previous.pem", "PUBLIC KEY", der)
+
+	tests := []struct {
+		name  string
+		opts  Options
+		valid bool
+	}{
+		{"HS256", Options{Algorithm: HS256, Secret: testSecret}, true},
+		{"HS256 with previous secrets", Options{Algorithm: HS256, Secret: testSecret, PreviousSecrets: []string{"old=" + oldTestSecret, oldTestSecret + "x"}}, true},
+		{"short previous secret", Options{Algorithm: HS256, Secret: testSecret, PreviousSecrets: []string{"old=short"}}, false},
+		{"short previous secret behind a kid", Options{Algorithm: HS256, Secret: testSecret, PreviousSecrets: []string{"kid=" + strings.Repeat("a", minSecretLength-1)}}, false},
+		{"RS256 with a previous public key", Options{Algorithm: RS256, PrivateKeyFile: private, KeyID: "new", PreviousPublicKeyFiles: []string{"old=" + previous}}, true},
+		{"algorithm mismatch", Options{Algorithm: EdDSA, PrivateKeyFile: private}, false},
+		{"missing private key", Options{Algorithm: RS256, PrivateKeyFile: filepath.Join(dir, "missing.pem")}, false},
+		{"missing previous public key", Options{Algorithm: RS256, PrivateKeyFile: private, PreviousPublicKeyFiles: []string{filepath.Join(dir, "missing.pem")}}, false},
+		{"unsupported algorithm", Options{Algorithm: "HS512", Secret: testSecret}, false},
+	}
+
+	for _, tt := range tests {
+		_, err := LoadManager(tt.opts)
+		if tt.valid && err != nil {
+			t.Errorf("%s: LoadManager = %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: LoadManager accepted invalid options", tt.name)
+		}
+	}
+
+	// A previous public key keeps the kid it was given, so its tokens still verify
+	manager, err := LoadManager(Options{Algorithm: RS256, PrivateKeyFile: private, PreviousPublicKeyFiles: []string{"old=" + previous}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Parse(sign(t, jwt.SigningMethodRS256, "old", oldRSA, validClaims())); err != nil {
+		t.Errorf("a token of the previous key gave %v", err)
+	}
+}
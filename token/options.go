@@ -0,0 +1,87 @@
+package token
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Supported signing algorithms
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// Options describes the keys a Manager is built from
+type Options struct {
+	Algorithm              string   // HS256, RS256 or EdDSA
+	Secret                 string   // HS256 signing secret
+	PrivateKeyFile         string   // PEM private key for RS256 or EdDSA
+	KeyID                  string   // kid of the signing key; derived from the key when empty
+	PreviousSecrets        []string // Older HS256 secrets that are still accepted, as "secret" or "kid=secret"
+	PreviousPublicKeyFiles []string // Older RS256/EdDSA public keys that are still accepted, as "path" or "kid=path"
+}
+
+// minSecretLength is the shortest HS256 secret accepted, as for JWT_SECRET
+const minSecretLength = 32
+
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// SplitKeyID splits a previous key entry written as "kid=value". A key that was
+// signing with an explicit JWT_KEY_ID must keep that kid, or the tokens it
+// signed would no longer verify. Without a kid, or with an empty one ("=value",
+// for values that contain "="), the kid is derived from the key.
+func SplitKeyID(entry string) (kid, value string) {
+	if i := strings.Index(entry, "="); i >= 0 && (i == 0 || keyIDPattern.MatchString(entry[:i])) {
+		return entry[:i], entry[i+1:]
+	}
+	return "", entry
+}
+
+// LoadManager builds a Manager from Options, reading key files from disk
+func LoadManager(opts Options) (*Manager, error) {
+	var signing *Key
+	switch opts.Algorithm {
+	case HS256:
+		signing = NewHMACKey([]byte(opts.Secret), opts.KeyID)
+	case RS256, EdDSA:
+		data, err := os.ReadFile(opts.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key: %w", err)
+		}
+		signing, err = ParsePrivateKeyPEM(data, opts.KeyID)
+		if err != nil {
+			return nil, err
+		}
+		if signing.Method.Alg() != opts.Algorithm {
+			return nil, fmt.Errorf("private key is a %s key, but the algorithm is %s", signing.Method.Alg(), opts.Algorithm)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", opts.Algorithm)
+	}
+
+	var previous []*Key
+	for i, entry := range opts.PreviousSecrets {
+		kid, secret := SplitKeyID(entry)
+		if len(secret) < minSecretLength {
+			return nil, fmt.Errorf("previous secret #%d must be at least %d characters long", i+1, minSecretLength)
+		}
+		previous = append(previous, NewHMACKey([]byte(secret), kid))
+	}
+	for _, entry := range opts.PreviousPublicKeyFiles {
+		kid, file := SplitKeyID(entry)
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key %s: %w", file, err)
+		}
+		key, err := ParsePublicKeyPEM(data, kid)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		previous = append(previous, key)
+	}
+
+	return NewManager(signing, previous...)
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// VersionETag returns the strong entity tag of a resource version
+func VersionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// MatchesVersion reports whether version is one of versions, where nil stands for "*" and matches any
+func MatchesVersion(versions []int64, version int64) bool {
+	if versions == nil {
+		return true
+	}
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseVersionETags reads the versions listed in an If-Match or If-None-Match
+// header. A nil result stands for "*". Weak tags are returned only when weak is
+// set, since If-Match compares strongly and If-None-Match weakly. Tags that are
+// not ours are skipped, so they simply never match.
+func ParseVersionETags(header string, weak bool) []int64 {
+	if strings.TrimSpace(header) == "*" {
+		return nil
+	}
+
+	versions := []int64{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		version, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 64)
+		if err == nil {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
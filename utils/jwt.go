@@ -1,51 +0,0 @@
-package utils
-
-import (
-	"job-portal/models"
-	"time"
-
-	"github.com/dgrijalva/jwt-go"
-)
-
-var JwtKey = []byte("secret_key")
-
-type Claims struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
-	jwt.StandardClaims
-}
-
-func GenerateJWT(user models.User) (string, error) {
-	// Define token claims
-	claims := jwt.MapClaims{
-		"user_id": user.ID.Hex(),
-		"email":   user.Email,
-		"role":    user.Role,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(), // Token expiry (72 hours)
-	}
-
-	// Create the token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign the token with the secret key
-	signedToken, err := token.SignedString(JwtKey)
-	if err != nil {
-		return "", err
-	}
-
-	return signedToken, nil
-}
-
-func VerifyJWT(tokenString string) (*Claims, error) {
-	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return JwtKey, nil
-	})
-	if err != nil {
-		return nil, err
-	}
-	if !token.Valid {
-		return nil, err
-	}
-	return claims, nil
-}
@@ -0,0 +1,29 @@
+package utils
+
+// MergePatch applies an RFC 7396 JSON Merge Patch to a decoded JSON document.
+// Objects are merged member by member, null removes a member, and any other
+// value, arrays included, replaces the target outright.
+func MergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	merged := make(map[string]interface{}, len(targetObject))
+	for key, value := range targetObject {
+		merged[key] = value
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = MergePatch(merged[key], value)
+	}
+	return merged
+}
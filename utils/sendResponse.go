@@ -12,8 +12,10 @@ type LoginResponse struct {
 	Status  int    `json:"status"`
 	Message string `json:"message"`
 	Data    struct {
-		Token string `json:"token"`
-		User  struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+		CSRFToken    string `json:"csrf_token"` // Send back in the X-CSRF-Token header when authenticating with cookies
+		User         struct {
 			ID    string `json:"id"`
 			Email string `json:"email"`
 			Role  string `json:"role"`
@@ -33,12 +35,13 @@ func SendResponse(c echo.Context, statusCode int, message string, data interface
 }
 
 // CreateLoginResponse creates a LoginResponse object
-func CreateLoginResponse(token string, userID, email, role string) LoginResponse {
+func CreateLoginResponse(token, refreshToken string, userID, email, role string) LoginResponse {
 	var response LoginResponse
 	response.Success = true
 	response.Status = http.StatusOK
 	response.Message = "Login successful"
 	response.Data.Token = token
+	response.Data.RefreshToken = refreshToken
 	response.Data.User.ID = userID
 	response.Data.User.Email = email
 	response.Data.User.Role = role
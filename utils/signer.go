@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidSignature is returned for a signed value that was tampered with or is malformed
+var ErrInvalidSignature = errors.New("invalid or tampered value")
+
+// Signer turns values into opaque, tamper-proof strings, such as pagination cursors
+type Signer struct {
+	key []byte
+}
+
+// NewSigner creates a Signer with the given HMAC key
+func NewSigner(key []byte) *Signer {
+	return &Signer{key: key}
+}
+
+// Sign encodes v as JSON and appends an HMAC-SHA256 signature
+func (s *Signer) Sign(v interface{}) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded)), nil
+}
+
+// Verify checks the signature of a string made by Sign and decodes its value into v
+func (s *Signer) Verify(signed string, v interface{}) error {
+	encoded, signature, ok := strings.Cut(signed, ".")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.mac(encoded)) {
+		return ErrInvalidSignature
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (s *Signer) mac(encoded string) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(encoded))
+	return h.Sum(nil)
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxSlugLength keeps slugs readable in URLs, counted in characters
+const maxSlugLength = 60
+
+// latinFolds spells out Latin letters that do not decompose into a base letter and accents
+var latinFolds = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'ł': "l", 'þ': "th", 'ı': "i",
+}
+
+// Slugify turns a name into a lower-case slug such as "acme-labs". Accented
+// Latin letters lose their accents ("Café" becomes "cafe"), letters and digits
+// of other scripts are kept as they are, and runs of anything else become a
+// single hyphen. A name without letters or digits gives "".
+func Slugify(name string) string {
+	var b strings.Builder
+	length := 0
+	hyphen := false
+	write := func(s string) {
+		if hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+			length++
+		}
+		b.WriteString(s)
+		length += len([]rune(s))
+		hyphen = false
+	}
+
+	for _, r := range norm.NFC.String(strings.ToLower(name)) {
+		if length >= maxSlugLength {
+			break
+		}
+		switch {
+		case latinFolds[r] != "":
+			write(latinFolds[r])
+		case unicode.Is(unicode.Latin, r):
+			write(string([]rune(norm.NFD.String(string(r)))[0])) // The base letter without its accents
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.M, r) && b.Len() > 0 && !hyphen:
+			write(string(r)) // Marks stay with the letters they belong to, as in Devanagari
+		default:
+			hyphen = true
+		}
+	}
+
+	slug := []rune(b.String())
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+	}
+	return strings.TrimRight(string(slug), "-")
+}
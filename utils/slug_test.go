@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Acme Labs", "acme-labs"},
+		{"  Acme -- Labs, Inc. ", "acme-labs-inc"},
+		{"Café Ünïcode", "cafe-unicode"},
+		{"Straße & Søn", "strasse-son"},
+		{"Łódź Software", "lodz-software"},
+		{"Яндекс", "яндекс"},
+		{"株式会社 ソニー", "株式会社-ソニー"},
+		{"हिन्दी Labs", "हिन्दी-labs"},
+		{"Ünï", "uni"},
+		{"???", ""},
+		{"", ""},
+		{strings.Repeat("ab ", 40), strings.Repeat("ab-", 19) + "ab"},
+		{strings.Repeat("é", 70), strings.Repeat("e", 60)},
+	}
+
+	for _, tt := range tests {
+		if got := Slugify(tt.name); got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}